@@ -0,0 +1,180 @@
+package assets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func static() fstest.MapFS {
+	return fstest.MapFS{
+		"css/main.css":       {Data: []byte("body{}")},
+		"scripts/htmx.js":    {Data: []byte("htmx")},
+		"fonts/a.woff2":      {Data: []byte("font")},
+		"LICENSE":            {Data: []byte("MIT")},
+		".hidden":            {Data: []byte("x")},
+		".git/config":        {Data: []byte("x")},
+		"css/other/main.css": {Data: []byte("body{}")},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	m, err := Build(static())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"LICENSE", "css/main.css", "css/other/main.css", "fonts/a.woff2", "scripts/htmx.js"}
+	if got := m.Paths(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Paths() = %q, want %q", got, want)
+	}
+
+	hashed := m.Path("css/main.css")
+	if !strings.HasPrefix(hashed, "css/main.") || !strings.HasSuffix(hashed, ".css") || len(hashed) != len("css/main..css")+hashLength {
+		t.Errorf("Path(css/main.css) = %q", hashed)
+	}
+	if got := m.Path("/css/main.css"); got != hashed {
+		t.Errorf("Path(/css/main.css) = %q, want %q", got, hashed)
+	}
+	if other := m.Path("css/other/main.css"); strings.TrimPrefix(other, "css/other/") != strings.TrimPrefix(hashed, "css/") {
+		t.Errorf("identical content hashed differently: %q and %q", hashed, other)
+	}
+	if got, ok := m.Original(hashed); !ok || got != "css/main.css" {
+		t.Errorf("Original(%q) = %q, %v", hashed, got, ok)
+	}
+	if got := m.Path("missing.js"); got != "missing.js" {
+		t.Errorf("Path(missing.js) = %q, want it unchanged", got)
+	}
+
+	err = m.Require("css/main.css", "missing.js", "img/logo.svg")
+	if err == nil || !strings.Contains(err.Error(), "missing.js") || !strings.Contains(err.Error(), "img/logo.svg") {
+		t.Errorf("Require() = %v, want both missing assets named", err)
+	}
+
+	var nilManifest *Manifest
+	if got := nilManifest.Path("css/main.css"); got != "css/main.css" {
+		t.Errorf("nil Path() = %q", got)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	dst := t.TempDir()
+	built, err := Fingerprint(static(), dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(built.Path("css/main.css"))))
+	if err != nil || string(b) != "body{}" {
+		t.Fatalf("hashed copy = %q, %v", b, err)
+	}
+
+	loaded, err := Load(os.DirFS(dst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range built.Paths() {
+		if loaded.Path(p) != built.Path(p) {
+			t.Errorf("loaded %s = %q, want %q", p, loaded.Path(p), built.Path(p))
+		}
+	}
+}
+
+func TestFileServer(t *testing.T) {
+	src := static()
+	m, _ := Build(src)
+	dst := t.TempDir()
+	if _, err := Fingerprint(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	servers := map[string]http.Handler{
+		"source": FileServer(src, m),
+		"built":  FileServer(os.DirFS(dst), m),
+	}
+	for name, h := range servers {
+		for _, p := range []string{"css/main.css", m.Path("css/main.css"), "fonts/a.woff2"} {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+p, nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s: GET /%s = %d", name, p, rec.Code)
+			}
+		}
+		for _, p := range []string{"/css/", "/nope.css", "/../assets.go"} {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("%s: GET %s = %d, want 404", name, p, rec.Code)
+			}
+		}
+	}
+}
+
+func TestFileServerCaching(t *testing.T) {
+	src := fstest.MapFS{"css/main.css": {Data: []byte(strings.Repeat("body { color: red; }\n", 50))}}
+	dst := t.TempDir()
+	m, err := Fingerprint(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := Precompress(dst)
+	if err != nil || n < 2 {
+		t.Fatalf("Precompress() = %d, %v, want at least the .br and .gz of main.css", n, err)
+	}
+	if again, _ := Build(os.DirFS(dst)); again.Require(m.Path("css/main.css")+".br") == nil {
+		t.Error("Build fingerprinted a precompressed sibling")
+	}
+
+	h := FileServer(os.DirFS(dst), m)
+	hashed := "/" + m.Path("css/main.css")
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		encoding string
+		cache    string
+	}{
+		{"hashed brotli", hashed, "gzip, deflate, br", "br", "immutable"},
+		{"hashed gzip", hashed, "gzip", "gzip", "immutable"},
+		{"brotli refused", hashed, "br;q=0, gzip;q=0.5", "gzip", "immutable"},
+		{"identity", hashed, "", "", "immutable"},
+		{"original", "/css/main.css", "br", "br", "no-cache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept-Encoding", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.encoding)
+			}
+			if got := rec.Header().Get("Cache-Control"); !strings.Contains(got, tt.cache) {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.cache)
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/css") {
+				t.Errorf("Content-Type = %q", got)
+			}
+			etag := rec.Header().Get("ETag")
+			if etag == "" {
+				t.Fatal("no ETag")
+			}
+
+			r.Header.Set("If-None-Match", etag)
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			if rec.Code != http.StatusNotModified {
+				t.Errorf("revalidation status = %d, want 304", rec.Code)
+			}
+		})
+	}
+}
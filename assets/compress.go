@@ -0,0 +1,90 @@
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressible are the extensions of text based files worth precompressing;
+// fonts and images are already compressed.
+var compressible = map[string]bool{
+	".css":         true,
+	".js":          true,
+	".mjs":         true,
+	".json":        true,
+	".map":         true,
+	".svg":         true,
+	".html":        true,
+	".txt":         true,
+	".xml":         true,
+	".ico":         true,
+	".webmanifest": true,
+}
+
+// Precompress writes .br and .gz siblings next to every compressible file under
+// dir, skipping those that would not be at least 10% smaller. It returns the
+// number of files written.
+func Precompress(dir string) (int, error) {
+	written := 0
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !compressible[strings.ToLower(filepath.Ext(p))] {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range encodings {
+			var buf bytes.Buffer
+			if err := compress(&buf, e.coding, b); err != nil {
+				return err
+			}
+			if buf.Len() > len(b)*9/10 {
+				continue
+			}
+			if err := os.WriteFile(p+e.ext, buf.Bytes(), 0o644); err != nil {
+				return err
+			}
+			written++
+		}
+		return nil
+	})
+	return written, err
+}
+
+func compress(w io.Writer, coding string, b []byte) error {
+	var zw io.WriteCloser
+	if coding == "br" {
+		zw = brotli.NewWriterLevel(w, brotli.BestCompression)
+	} else {
+		gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+		if err != nil {
+			return err
+		}
+		zw = gz
+	}
+	if _, err := zw.Write(b); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// isSibling reports whether p is a precompressed copy of another file in fsys.
+func isSibling(fsys fs.FS, p string) bool {
+	for _, e := range encodings {
+		if original, ok := strings.CutSuffix(p, e.ext); ok {
+			if _, err := fs.Stat(fsys, original); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
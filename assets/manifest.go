@@ -0,0 +1,197 @@
+// Package assets fingerprints static files into content hashed names such as
+// css/main.min.3f2a9c81d0.css, so they can be cached forever, and maps their
+// original paths to those names through a Manifest.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ManifestName is the file Fingerprint writes the manifest to.
+const ManifestName = "manifest.json"
+
+// hashLength is the number of hex digits of the content hash kept in names.
+const hashLength = 10
+
+// Manifest maps the slash separated path of every static file, relative to
+// the static root, to its fingerprinted path.
+type Manifest struct {
+	files    map[string]string // path → hashed path
+	original map[string]string // hashed path → path
+}
+
+// Build hashes every file in fsys. Dot files, precompressed siblings and an
+// existing manifest are skipped.
+func Build(fsys fs.FS) (*Manifest, error) {
+	m := newManifest()
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != "." {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || p == ManifestName || isSibling(fsys, p) {
+			return nil
+		}
+
+		f, err := fsys.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return fmt.Errorf("hash %s: %w", p, err)
+		}
+		m.add(p, HashedName(p, hex.EncodeToString(h.Sum(nil))[:hashLength]))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Load reads the manifest written by Fingerprint from fsys, or builds one in
+// memory when there is none, as in development.
+func Load(fsys fs.FS) (*Manifest, error) {
+	b, err := fs.ReadFile(fsys, ManifestName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Build(fsys)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string]string
+	if err := json.Unmarshal(b, &files); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestName, err)
+	}
+	m := newManifest()
+	for p, hashed := range files {
+		m.add(p, hashed)
+	}
+	return m, nil
+}
+
+// Fingerprint copies every file in src to its hashed path under dst and writes
+// the manifest next to them.
+func Fingerprint(src fs.FS, dst string) (*Manifest, error) {
+	m, err := Build(src)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range m.Paths() {
+		if err := copyFile(src, p, filepath.Join(dst, filepath.FromSlash(m.files[p]))); err != nil {
+			return nil, err
+		}
+	}
+	b, err := json.MarshalIndent(m.files, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return m, os.WriteFile(filepath.Join(dst, ManifestName), append(b, '\n'), 0o644)
+}
+
+// HashedName inserts hash before the extension of p:
+// "css/main.min.css" becomes "css/main.min.<hash>.css".
+func HashedName(p, hash string) string {
+	ext := path.Ext(p)
+	return strings.TrimSuffix(p, ext) + "." + hash + ext
+}
+
+// Path returns the hashed path of p, or p itself if it is not in the manifest.
+// A nil Manifest returns p.
+func (m *Manifest) Path(p string) string {
+	if hashed, ok := m.Lookup(p); ok {
+		return hashed
+	}
+	return p
+}
+
+// Lookup returns the hashed path of p and whether p is in the manifest.
+func (m *Manifest) Lookup(p string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	hashed, ok := m.files[strings.TrimPrefix(p, "/")]
+	return hashed, ok
+}
+
+// Original returns the path a hashed path was fingerprinted from.
+func (m *Manifest) Original(hashed string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	p, ok := m.original[strings.TrimPrefix(hashed, "/")]
+	return p, ok
+}
+
+// Require returns an error naming every path that is not in the manifest.
+func (m *Manifest) Require(paths ...string) error {
+	var errs []error
+	for _, p := range paths {
+		if _, ok := m.Lookup(p); !ok {
+			errs = append(errs, fmt.Errorf("missing asset %s", p))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Paths returns the original paths in the manifest, sorted.
+func (m *Manifest) Paths() []string {
+	if m == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(m.files))
+	for p := range m.files {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func newManifest() *Manifest {
+	return &Manifest{files: make(map[string]string), original: make(map[string]string)}
+}
+
+func (m *Manifest) add(p, hashed string) {
+	m.files[p] = hashed
+	m.original[hashed] = p
+}
+
+func copyFile(src fs.FS, name, dst string) error {
+	in, err := src.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,166 @@
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// encodings are the precompressed siblings FileServer looks for, in order of
+// preference.
+var encodings = []struct {
+	coding string
+	ext    string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// FileServer serves the files of fsys, such as a //go:embed static directory,
+// by their original or hashed path. It works both on a source static directory
+// with an in memory manifest and on the output of hgmx build, where only
+// hashed files exist and stylesheets still link fonts and images by their
+// original names.
+//
+// Hashed paths are cached as immutable, other paths are revalidated with their
+// ETag. When the client accepts it, a .br or .gz sibling written by Precompress
+// is sent instead. Directories are not listed. Mount it with http.StripPrefix:
+//
+//	mux.Handle("/static/", http.StripPrefix("/static", assets.FileServer(fsys, m)))
+func FileServer(fsys fs.FS, m *Manifest) http.Handler {
+	return &fileServer{fsys: fsys, m: m}
+}
+
+type fileServer struct {
+	fsys  fs.FS
+	m     *Manifest
+	etags sync.Map // file → content hash, for files outside the manifest
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, hash, immutable := s.resolve(strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/"))
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	h := w.Header()
+	if immutable {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		h.Set("Cache-Control", "no-cache")
+	}
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		h.Set("Content-Type", ctype)
+	}
+	if hash == "" {
+		if hash, err = s.etag(name); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	served := name
+	h.Add("Vary", "Accept-Encoding")
+	for _, e := range encodings {
+		if !accepts(r, e.coding) {
+			continue
+		}
+		if _, err := fs.Stat(s.fsys, name+e.ext); err == nil {
+			served = name + e.ext
+			h.Set("Content-Encoding", e.coding)
+			hash += "-" + e.coding
+			break
+		}
+	}
+	h.Set("ETag", `"`+hash+`"`)
+
+	f, err := s.fsys.Open(served)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(b)
+	}
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// resolve returns the file to open for a requested path, its content hash if
+// the manifest knows it, and whether the request named the hashed path.
+func (s *fileServer) resolve(requested string) (name, hash string, immutable bool) {
+	if original, ok := s.m.Original(requested); ok {
+		return s.existing(requested, original), hashOf(requested), true
+	}
+	if hashed, ok := s.m.Lookup(requested); ok {
+		return s.existing(requested, hashed), hashOf(hashed), false
+	}
+	return requested, "", false
+}
+
+// existing returns name if it exists in fsys, otherwise alt.
+func (s *fileServer) existing(name, alt string) string {
+	if _, err := fs.Stat(s.fsys, name); err == nil {
+		return name
+	}
+	return alt
+}
+
+// etag returns the content hash of a file outside the manifest, computing it
+// once.
+func (s *fileServer) etag(name string) (string, error) {
+	if hash, ok := s.etags.Load(name); ok {
+		return hash.(string), nil
+	}
+	b, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])[:hashLength]
+	s.etags.Store(name, hash)
+	return hash, nil
+}
+
+// hashOf returns the hash HashedName put in hashed.
+func hashOf(hashed string) string {
+	base := strings.TrimSuffix(hashed, path.Ext(hashed))
+	return base[strings.LastIndex(base, ".")+1:]
+}
+
+// accepts reports whether the Accept-Encoding header of r allows coding.
+func accepts(r *http.Request, coding string) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			name = strings.TrimSpace(name)
+			if !strings.EqualFold(name, coding) && name != "*" {
+				continue
+			}
+			q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if !ok {
+				return true
+			}
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+	}
+	return false
+}
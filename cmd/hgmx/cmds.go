@@ -1,14 +1,39 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
 	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/nosvagor/hgmx"
+	"github.com/nosvagor/hgmx/assets"
+	"github.com/nosvagor/hgmx/fonts"
+	"github.com/nosvagor/hgmx/internal/bundle"
+	"github.com/nosvagor/hgmx/internal/critical"
+	"github.com/nosvagor/hgmx/internal/dev"
+	"github.com/nosvagor/hgmx/internal/favicon"
+	"github.com/nosvagor/hgmx/internal/icons"
 	"github.com/nosvagor/hgmx/internal/palette"
+	"github.com/nosvagor/hgmx/internal/project"
+	"github.com/nosvagor/hgmx/internal/tailwind"
+	"github.com/nosvagor/hgmx/scripts"
 )
 
 // --- info command ---
@@ -31,6 +56,7 @@ func infoCmd() (code int) {
 var components = map[string]string{
 	"button": "action",
 	"avatar": "display",
+	"icon":   "display",
 	"text":   "display",
 	"loader": "feedback",
 	"input":  "input",
@@ -71,6 +97,15 @@ func initCmd(args []string) (code int) {
 		}
 	}
 
+	if err := copyDir(location{fs: hgmx.LibraryFS, source: LIB_DIR + "/icons", destination: filepath.Join(viewsDir, "icons")}); err != nil {
+		log.Error("Failed to copy icons", slog.String("error", err.Error()))
+		return 1
+	}
+	if err := copyEmbedFile(location{fs: hgmx.LibraryFS, source: LIB_DIR + "/components/display/icons.go", destination: filepath.Join(viewsDir, "components", "display", "icons.go")}); err != nil {
+		log.Error("Failed to copy icon names", slog.String("error", err.Error()))
+		return 1
+	}
+
 	for file, dir := range blocks {
 		dir := filepath.Join("blocks", dir)
 		if err := addComponent(location{fs: hgmx.LibraryFS, source: dir, destination: filepath.Join(viewsDir, dir), file: file}); err != nil {
@@ -116,9 +151,38 @@ func paletteCmd(args []string) (code int) {
 
 	log.Info("Generating palette for color:", slog.String("hex", hexColor))
 
-	generatedPalette := palette.Generate(hexColor)
+	generatedPalette := palette.Generate(hexColor).WithAlphas(paletteAlphas...)
+
+	if paletteEqualize {
+		generatedPalette.Equalize()
+		log.Debug("Shades redistributed to equal perceptual spacing")
+	}
+
+	if paletteCheck {
+		uneven := 0
+		for _, u := range generatedPalette.Uniformity(palette.DefaultTolerance) {
+			u.ToReport(os.Stdout)
+			if !u.OK() {
+				uneven++
+			}
+		}
+		if uneven > 0 {
+			log.Warn("Some shade scales are not perceptually uniform", slog.Int("scales", uneven))
+		}
+	}
+
+	if palettePreview || palettePNG != "" || paletteSVG != "" {
+		return paletteExport(log, generatedPalette)
+	}
+
+	if paletteFormat != "css" {
+		return paletteScheme(log, generatedPalette)
+	}
 
 	outputFile := "library/static/css/colors.css"
+	if paletteOutput != "" {
+		outputFile = paletteOutput
+	}
 	f, err := os.Create(outputFile)
 	if err != nil {
 		log.Error("Failed to open output file for writing", slog.String("file", outputFile), slog.String("error", err.Error()))
@@ -131,6 +195,1126 @@ func paletteCmd(args []string) (code int) {
 	return 0
 }
 
+func paletteScheme(log *slog.Logger, p palette.Palette) (code int) {
+	scheme := p.Scheme("hgmx")
+	format := palette.Format(paletteFormat)
+	if !slices.Contains(palette.Formats, format) {
+		log.Error("Unknown color scheme format", slog.String("format", paletteFormat), slog.Any("formats", palette.Formats))
+		return 1
+	}
+
+	if paletteOutput == "" {
+		if err := scheme.Write(os.Stdout, format); err != nil {
+			log.Error("Failed to write color scheme", slog.String("format", paletteFormat), slog.String("error", err.Error()))
+			return 1
+		}
+		return 0
+	}
+
+	f, err := os.Create(paletteOutput)
+	if err != nil {
+		log.Error("Failed to open output file for writing", slog.String("file", paletteOutput), slog.String("error", err.Error()))
+		return 1
+	}
+	if err := scheme.Write(f, format); err != nil {
+		f.Close()
+		log.Error("Failed to write color scheme", slog.String("format", paletteFormat), slog.String("error", err.Error()))
+		return 1
+	}
+	if err := f.Close(); err != nil {
+		log.Error("Failed to close output file", slog.String("file", paletteOutput), slog.String("error", err.Error()))
+		return 1
+	}
+
+	log.Info("Color scheme successfully generated and written", slog.String("format", paletteFormat), slog.String("file", paletteOutput))
+	return 0
+}
+
+func paletteExport(log *slog.Logger, p palette.Palette) (code int) {
+	if palettePreview {
+		colorterm := os.Getenv("COLORTERM")
+		p.ToANSI(os.Stdout, colorterm == "truecolor" || colorterm == "24bit")
+	}
+
+	exports := []struct {
+		file  string
+		write func(io.Writer) error
+	}{
+		{palettePNG, p.ToPNG},
+		{paletteSVG, p.ToSVG},
+	}
+	for _, export := range exports {
+		if export.file == "" {
+			continue
+		}
+		f, err := os.Create(export.file)
+		if err != nil {
+			log.Error("Failed to open output file for writing", slog.String("file", export.file), slog.String("error", err.Error()))
+			return 1
+		}
+		if err := export.write(f); err != nil {
+			f.Close()
+			log.Error("Failed to render palette", slog.String("file", export.file), slog.String("error", err.Error()))
+			return 1
+		}
+		if err := f.Close(); err != nil {
+			log.Error("Failed to close output file", slog.String("file", export.file), slog.String("error", err.Error()))
+			return 1
+		}
+		log.Info("Palette swatches written", slog.String("file", export.file))
+	}
+
+	return 0
+}
+
+// --- build command ---
+
+func buildCmd(input, output string) (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, err := project.Load(".")
+	if err != nil {
+		log.Error("Failed to read project config", slog.String("error", err.Error()))
+		return 1
+	}
+	if input == "" {
+		input = cfg.Path(cfg.Build.Static)
+	}
+	if output == "" {
+		output = cfg.Path(cfg.Build.Output)
+	}
+	if _, err := os.Stat(input); err != nil {
+		log.Error("Static directory not found", slog.String("dir", input), slog.String("error", err.Error()))
+		return 1
+	}
+	if err := bundle.CheckOutput(input, output); err != nil {
+		log.Error("Invalid build output", slog.String("error", err.Error()))
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	templBin, err := exec.LookPath("templ")
+	if err != nil {
+		log.Error("templ not found on PATH, install it with: go install github.com/a-h/templ/cmd/templ@latest")
+		return 1
+	}
+	gen := exec.CommandContext(ctx, templBin, "generate", "-path", cfg.Path(cfg.Views))
+	gen.Stdout, gen.Stderr = io.Discard, os.Stderr
+	if err := gen.Run(); err != nil {
+		log.Error("templ generate failed", slog.String("error", err.Error()))
+		return 1
+	}
+	log.Info("Templates generated", slog.String("views", cfg.Views))
+
+	if err := regenerateSafelist(log, cfg); err != nil {
+		log.Error("Failed to write safelist", slog.String("file", cfg.CSS.Safelist), slog.String("error", err.Error()))
+		return 1
+	}
+	minify := true
+	cfg.CSS.Minify = &minify
+	tw, err := tailwindCommand(ctx, log, cfg, false)
+	if err != nil {
+		log.Error("Failed to locate tailwindcss", slog.String("error", err.Error()))
+		return 1
+	}
+	if err := tw.Run(); err != nil {
+		log.Error("tailwindcss failed", slog.String("error", err.Error()))
+		return 1
+	}
+	log.Info("CSS built", slog.String("output", cfg.CSS.Output))
+
+	if len(cfg.Critical.Pages) > 0 {
+		if err := extractCritical(ctx, log, cfg); err != nil {
+			log.Error("Failed to extract critical CSS", slog.String("error", err.Error()))
+			return 1
+		}
+	}
+
+	// only the files the previous manifest lists are pruned, never a manifest
+	// Load would build from whatever the output holds
+	var prev *assets.Manifest
+	if _, err := os.Stat(filepath.Join(output, assets.ManifestName)); err == nil {
+		if prev, err = assets.Load(os.DirFS(output)); err != nil {
+			log.Error("Failed to read the manifest of the previous build", slog.String("dir", output), slog.String("error", err.Error()))
+			return 1
+		}
+	}
+	m, err := assets.Fingerprint(os.DirFS(input), output)
+	if err != nil {
+		log.Error("Failed to fingerprint static files", slog.String("dir", input), slog.String("error", err.Error()))
+		return 1
+	}
+	for _, p := range m.Paths() {
+		log.Debug("Fingerprinted", slog.String("file", p), slog.String("as", m.Path(p)))
+	}
+	pruned, err := bundle.Prune(output, prev, m)
+	if err != nil {
+		log.Error("Failed to remove stale files", slog.String("dir", output), slog.String("error", err.Error()))
+		return 1
+	}
+	log.Info("Static files fingerprinted", slog.Int("files", len(m.Paths())), slog.Int("stale", pruned), slog.String("output", output), slog.String("manifest", filepath.Join(output, assets.ManifestName)))
+
+	compressed, err := assets.Precompress(output)
+	if err != nil {
+		log.Error("Failed to precompress static files", slog.String("dir", output), slog.String("error", err.Error()))
+		return 1
+	}
+	log.Info("Static files precompressed", slog.Int("files", compressed))
+
+	viewRefs, err := bundle.Views(os.DirFS(cfg.Path(cfg.Views)))
+	if err != nil {
+		log.Error("Failed to scan views for assets", slog.String("error", err.Error()))
+		return 1
+	}
+	staticRefs, err := bundle.Static(os.DirFS(input))
+	if err != nil {
+		log.Error("Failed to scan static files for assets", slog.String("error", err.Error()))
+		return 1
+	}
+	missing := 0
+	for _, ref := range append(viewRefs, staticRefs...) {
+		if _, ok := m.Lookup(ref.Path); !ok {
+			log.Error("Missing asset", slog.String("ref", ref.String()))
+			missing++
+		}
+	}
+	if missing > 0 {
+		return 1
+	}
+	log.Info("Asset references verified", slog.Int("refs", len(viewRefs)+len(staticRefs)))
+
+	sizes, err := bundle.Sizes(os.DirFS(output), m)
+	if err != nil {
+		log.Error("Failed to measure bundle", slog.String("error", err.Error()))
+		return 1
+	}
+	budgets, err := bundle.Budgets(cfg.Build.Budgets, sizes)
+	if err != nil {
+		log.Error("Invalid size budget", slog.String("error", err.Error()))
+		return 1
+	}
+	if err := bundle.WriteReport(os.Stdout, sizes, budgets); err != nil {
+		log.Error("Failed to write size report", slog.String("error", err.Error()))
+		return 1
+	}
+	for _, b := range budgets {
+		if b.Exceeded() {
+			log.Error("Size budget exceeded", slog.String("pattern", b.Pattern), slog.String("size", bundle.FormatSize(b.Total)), slog.String("max", bundle.FormatSize(b.Max)))
+			code = 1
+		}
+	}
+	return code
+}
+
+// --- css command ---
+
+func cssCmd(watch bool) (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, err := project.Load(".")
+	if err != nil {
+		log.Error("Failed to read project config", slog.String("error", err.Error()))
+		return 1
+	}
+
+	if err := regenerateSafelist(log, cfg); err != nil {
+		log.Error("Failed to write safelist", slog.String("file", cfg.CSS.Safelist), slog.String("error", err.Error()))
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cmd, err := tailwindCommand(ctx, log, cfg, watch)
+	if err != nil {
+		log.Error("Failed to locate tailwindcss", slog.String("error", err.Error()))
+		return 1
+	}
+	if err := cmd.Run(); err != nil && ctx.Err() == nil {
+		log.Error("tailwindcss failed", slog.String("error", err.Error()))
+		return 1
+	}
+
+	if !watch {
+		log.Info("CSS built", slog.String("input", cfg.CSS.Input), slog.String("output", cfg.CSS.Output))
+	}
+	return 0
+}
+
+func cssCriticalCmd() (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, err := project.Load(".")
+	if err != nil {
+		log.Error("Failed to read project config", slog.String("error", err.Error()))
+		return 1
+	}
+	if len(cfg.Critical.Pages) == 0 {
+		log.Error("No critical pages configured", slog.String("config", project.FileName), slog.String("key", "critical.pages"))
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := extractCritical(ctx, log, cfg); err != nil {
+		log.Error("Failed to extract critical CSS", slog.String("error", err.Error()))
+		return 1
+	}
+	return 0
+}
+
+// extractCritical renders the configured pages and writes the rules of the
+// built stylesheet each needs above the fold to the critical directory.
+func extractCritical(ctx context.Context, log *slog.Logger, cfg *project.Config) error {
+	var pages []critical.Page
+	for _, name := range slices.Sorted(maps.Keys(cfg.Critical.Pages)) {
+		p, err := critical.ParsePage(name, cfg.Critical.Pages[name])
+		if err != nil {
+			return err
+		}
+		pages = append(pages, p)
+	}
+	css, err := os.ReadFile(cfg.Path(cfg.CSS.Output))
+	if err != nil {
+		return err
+	}
+	html, err := critical.Render(ctx, cfg.Root, cfg.Views, pages)
+	if err != nil {
+		return err
+	}
+
+	dir := cfg.Path(cfg.Critical.Dir)
+	rel, err := filepath.Rel(filepath.Dir(cfg.Path(cfg.CSS.Output)), dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, p := range pages {
+		out, err := critical.Extract(css, html[p.Name])
+		if err != nil {
+			return fmt.Errorf("page %s: %w", p.Name, err)
+		}
+		out = critical.Rebase(out, ".", filepath.ToSlash(rel))
+		file := filepath.Join(dir, p.Name+".css")
+		if err := os.WriteFile(file, out, 0o644); err != nil {
+			return err
+		}
+		log.Info("Critical CSS extracted", slog.String("page", p.Name), slog.String("file", file), slog.String("size", bundle.FormatSize(int64(len(out)))))
+		if len(out) > critical.MaxSize {
+			log.Warn("Critical CSS is larger than the first round trip, mark later content with "+critical.BelowFold, slog.String("page", p.Name), slog.String("max", bundle.FormatSize(critical.MaxSize)))
+		}
+	}
+	return nil
+}
+
+// tailwindCommand locates tailwindcss and returns the command building the
+// project stylesheet, writing its output to stderr.
+func tailwindCommand(ctx context.Context, log *slog.Logger, cfg *project.Config, watch bool) (*exec.Cmd, error) {
+	cli := &tailwind.CLI{
+		Version: cfg.Tailwind.Version,
+		Binary:  cfg.Path(cfg.Tailwind.Binary),
+		Offline: cssOffline || os.Getenv("HGMX_OFFLINE") != "",
+	}
+	bin, err := cli.Locate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("Using tailwindcss", slog.String("binary", bin))
+
+	build := tailwind.Build{
+		Dir:    cfg.Root,
+		Input:  cfg.CSS.Input,
+		Output: cfg.CSS.Output,
+		Minify: cfg.CSS.Minified(),
+		Watch:  watch,
+	}
+	cmd := build.Command(ctx, bin)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	return cmd, nil
+}
+
+// regenerateSafelist rewrites the safelist from the palette in colors.css, next
+// to the CSS input, and the color utility prefixes completed at runtime in the
+// views.
+func regenerateSafelist(log *slog.Logger, cfg *project.Config) error {
+	input := cfg.Path(cfg.CSS.Input)
+	safelist := cfg.Path(cfg.CSS.Safelist)
+	views := cfg.Path(cfg.Views)
+
+	colorsCSS, err := os.ReadFile(filepath.Join(filepath.Dir(input), "colors.css"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	colors := tailwind.Colors(colorsCSS)
+
+	prefixes, err := tailwind.DynamicPrefixes(os.DirFS(views))
+	if err != nil {
+		return err
+	}
+	sources, err := filepath.Rel(filepath.Dir(safelist), views)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tailwind.WriteSafelist(&buf, sources, colors, prefixes); err != nil {
+		return err
+	}
+	// Rewriting an unchanged safelist would make a watching Tailwind rebuild.
+	if old, err := os.ReadFile(safelist); err == nil && bytes.Equal(old, buf.Bytes()) {
+		return nil
+	}
+	if err := os.WriteFile(safelist, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	log.Debug("Safelist written", slog.String("file", safelist), slog.Any("prefixes", prefixes), slog.Int("colors", len(colors)))
+
+	if main, err := os.ReadFile(input); err == nil && !importsFile(main, filepath.Base(safelist)) {
+		log.Warn("CSS input does not import the safelist, dynamic classes will be missing", slog.String("input", cfg.CSS.Input), slog.String("safelist", filepath.Base(safelist)))
+	}
+	return nil
+}
+
+// importsFile reports whether css has an @import of name that is not
+// commented out.
+func importsFile(css []byte, name string) bool {
+	for _, line := range strings.Split(string(css), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "@import") && strings.Contains(line, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// --- fonts command ---
+
+func fontsAddCmd(args []string) (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, err := project.Load(".")
+	if err != nil {
+		log.Error("Failed to read project config", slog.String("error", err.Error()))
+		return 1
+	}
+	dir := cfg.Path(cfg.Fonts.Dir)
+	css := cfg.Path(cfg.Fonts.CSS)
+
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			log.Error("Font not found", slog.String("path", arg), slog.String("error", err.Error()))
+			return 1
+		}
+		if info.IsDir() {
+			matches, _ := filepath.Glob(filepath.Join(arg, "*.woff2"))
+			files = append(files, matches...)
+			continue
+		}
+		if filepath.Ext(arg) != ".woff2" {
+			log.Error("Only WOFF2 fonts are supported, convert it first, e.g. with woff2_compress", slog.String("file", arg))
+			return 1
+		}
+		files = append(files, arg)
+	}
+	if len(files) == 0 {
+		log.Error("No .woff2 files found", slog.Any("paths", args))
+		return 1
+	}
+
+	var subsets []fonts.Subset
+	for _, spec := range fontsSubsets {
+		s, err := fonts.ParseSubset(spec)
+		if err != nil {
+			log.Error("Invalid subset", slog.String("error", err.Error()))
+			return 1
+		}
+		subsets = append(subsets, s)
+	}
+	var pyftsubset string
+	if len(subsets) > 0 {
+		if pyftsubset, err = exec.LookPath("pyftsubset"); err != nil {
+			log.Error("Subsetting needs pyftsubset on PATH, install it with: pip install fonttools brotli")
+			return 1
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Error("Failed to create fonts directory", slog.String("dir", dir), slog.String("error", err.Error()))
+		return 1
+	}
+	reg, err := fonts.Load(os.DirFS(dir), fonts.RegistryName)
+	if err != nil {
+		log.Error("Failed to read font registry", slog.String("error", err.Error()))
+		return 1
+	}
+
+	var families []string
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			log.Error("Failed to read font", slog.String("file", file), slog.String("error", err.Error()))
+			return 1
+		}
+		info, err := fonts.Parse(b)
+		if err != nil {
+			log.Error("Failed to parse font", slog.String("file", file), slog.String("error", err.Error()))
+			return 1
+		}
+		if fontsFamily != "" {
+			info.Family = fontsFamily
+		}
+		if !slices.Contains(families, info.Family) {
+			families = append(families, info.Family)
+		}
+
+		name := filepath.Base(file)
+		var faces []fonts.Face
+		if len(subsets) == 0 {
+			if err := copyFont(file, filepath.Join(dir, name)); err != nil {
+				log.Error("Failed to copy font", slog.String("file", file), slog.String("error", err.Error()))
+				return 1
+			}
+			faces = append(faces, fonts.NewFace(name, info))
+		}
+		for _, s := range subsets {
+			out := filepath.Join(dir, s.File(name))
+			cmd := exec.Command(pyftsubset, file,
+				"--unicodes="+strings.ReplaceAll(s.Range, " ", ""),
+				"--layout-features=*",
+				"--flavor=woff2",
+				"--output-file="+out)
+			cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+			if err := cmd.Run(); err != nil {
+				log.Error("Failed to subset font", slog.String("file", file), slog.String("subset", s.Name), slog.String("error", err.Error()))
+				return 1
+			}
+			face := fonts.NewFace(s.File(name), info)
+			face.UnicodeRange = s.Range
+			faces = append(faces, face)
+		}
+
+		for _, face := range faces {
+			face.Preload = fontsPreload
+			reg.Add(face)
+			log.Info("Font added", slog.String("family", face.Family), slog.String("file", face.File), slog.String("style", face.Style), slog.String("weight", face.Weight))
+			for _, a := range face.Axes {
+				log.Debug("Variation axis", slog.String("file", face.File), slog.String("tag", a.Tag), slog.Float64("min", a.Min), slog.Float64("max", a.Max))
+			}
+		}
+	}
+
+	if fontsRole != "" {
+		if len(families) != 1 {
+			log.Error("--as needs fonts of a single family", slog.Any("families", families))
+			return 1
+		}
+		reg.SetRole(fontsRole, families[0])
+	}
+
+	b, err := reg.Marshal()
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, fonts.RegistryName), b, 0o644)
+	}
+	if err != nil {
+		log.Error("Failed to write font registry", slog.String("error", err.Error()))
+		return 1
+	}
+
+	rel, err := filepath.Rel(filepath.Dir(css), dir)
+	if err != nil {
+		log.Error("Fonts directory is not relative to the stylesheet", slog.String("error", err.Error()))
+		return 1
+	}
+	var buf bytes.Buffer
+	if err := reg.WriteCSS(&buf, filepath.ToSlash(rel)); err != nil {
+		log.Error("Failed to generate fonts.css", slog.String("error", err.Error()))
+		return 1
+	}
+	if err := os.WriteFile(css, buf.Bytes(), 0o644); err != nil {
+		log.Error("Failed to write fonts.css", slog.String("file", css), slog.String("error", err.Error()))
+		return 1
+	}
+	log.Info("Font faces written", slog.String("file", cfg.Fonts.CSS), slog.Int("faces", len(reg.Faces)))
+
+	if main, err := os.ReadFile(cfg.Path(cfg.CSS.Input)); err == nil && !importsFile(main, filepath.Base(css)) {
+		log.Warn("CSS input does not import the font faces", slog.String("input", cfg.CSS.Input), slog.String("fonts", filepath.Base(css)))
+	}
+	return 0
+}
+
+// copyFont copies src to dst unless they are the same file.
+func copyFont(src, dst string) error {
+	if a, err := filepath.Abs(src); err == nil {
+		if b, err := filepath.Abs(dst); err == nil && a == b {
+			return nil
+		}
+	}
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, b, 0o644)
+}
+
+// --- icons command ---
+
+func iconsAddCmd(args []string) (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, err := project.Load(".")
+	if err != nil {
+		log.Error("Failed to read project config", slog.String("error", err.Error()))
+		return 1
+	}
+	dir := cfg.Path(cfg.Icons.Dir)
+
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			log.Error("Icon not found", slog.String("path", arg), slog.String("error", err.Error()))
+			return 1
+		}
+		if info.IsDir() {
+			matches, _ := filepath.Glob(filepath.Join(arg, "*.svg"))
+			files = append(files, matches...)
+			continue
+		}
+		files = append(files, arg)
+	}
+	if len(files) == 0 {
+		log.Error("No .svg files found", slog.Any("paths", args))
+		return 1
+	}
+
+	names, err := icons.Names(files)
+	if err != nil {
+		log.Error("Invalid icon name", slog.String("error", err.Error()))
+		return 1
+	}
+	if !iconsForce {
+		for i, name := range names {
+			if _, err := os.Stat(filepath.Join(dir, name+".svg")); err == nil {
+				log.Error("Icon already exists, pass --force to replace it", slog.String("name", name), slog.String("file", files[i]))
+				code = 1
+			}
+		}
+		if code != 0 {
+			return code
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Error("Failed to create icons directory", slog.String("dir", dir), slog.String("error", err.Error()))
+		return 1
+	}
+	for i, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			log.Error("Failed to read icon", slog.String("file", file), slog.String("error", err.Error()))
+			return 1
+		}
+		name := names[i]
+		sym, err := icons.Optimize(name, b)
+		if err != nil {
+			log.Error("Failed to optimise icon", slog.String("file", file), slog.String("error", err.Error()))
+			return 1
+		}
+		svg := sym.SVG()
+		if err := os.WriteFile(filepath.Join(dir, name+".svg"), []byte(svg), 0o644); err != nil {
+			log.Error("Failed to write icon", slog.String("icon", name), slog.String("error", err.Error()))
+			return 1
+		}
+		log.Info("Icon added", slog.String("name", name), slog.String("const", icons.ConstName(name)), slog.Int("bytes", len(b)), slog.Int("optimised", len(svg)))
+	}
+
+	if err := rebuildIcons(log, cfg); err != nil {
+		log.Error("Failed to rebuild the icon sprite", slog.String("error", err.Error()))
+		return 1
+	}
+	return 0
+}
+
+// rebuildIcons builds the sprite and the icon constants from every icon in
+// the icons directory.
+func rebuildIcons(log *slog.Logger, cfg *project.Config) error {
+	files, err := filepath.Glob(filepath.Join(cfg.Path(cfg.Icons.Dir), "*.svg"))
+	if err != nil {
+		return err
+	}
+	symbols := make([]*icons.Symbol, 0, len(files))
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		sym, err := icons.Optimize(icons.Name(file), b)
+		if err != nil {
+			return err
+		}
+		symbols = append(symbols, sym)
+	}
+
+	var sprite bytes.Buffer
+	if err := icons.WriteSprite(&sprite, symbols); err != nil {
+		return err
+	}
+	spritePath := cfg.Path(cfg.Icons.Sprite)
+	if err := os.MkdirAll(filepath.Dir(spritePath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(spritePath, sprite.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	var src bytes.Buffer
+	goPath := cfg.Path(cfg.Icons.Go)
+	if err := icons.WriteGo(&src, filepath.Base(filepath.Dir(goPath)), symbols); err != nil {
+		return err
+	}
+	if err := os.WriteFile(goPath, src.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	log.Info("Icon sprite written", slog.String("sprite", cfg.Icons.Sprite), slog.String("go", cfg.Icons.Go), slog.Int("icons", len(symbols)), slog.Int("bytes", sprite.Len()))
+	return nil
+}
+
+// --- favicon command ---
+
+func faviconCmd(args []string) (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, err := project.Load(".")
+	if err != nil {
+		log.Error("Failed to read project config", slog.String("error", err.Error()))
+		return 1
+	}
+	fc := cfg.Favicon
+	if faviconName != "" {
+		fc.Name = faviconName
+	}
+	if faviconShortName != "" {
+		fc.ShortName = faviconShortName
+	}
+	if faviconShade != 0 {
+		fc.Shade = faviconShade
+	}
+	if fc.Name == "" {
+		fc.Name = moduleName(cfg.Root)
+	}
+	if fc.ShortName == "" {
+		fc.ShortName = fc.Name
+	}
+
+	source := args[0]
+	b, err := os.ReadFile(source)
+	if err != nil {
+		log.Error("Failed to read source image", slog.String("file", source), slog.String("error", err.Error()))
+		return 1
+	}
+	var svg []byte
+	var img image.Image
+	if strings.EqualFold(filepath.Ext(source), ".svg") {
+		svg = b
+		if faviconPNG != "" {
+			b, err = os.ReadFile(faviconPNG)
+			if err == nil {
+				img, err = favicon.Decode(b)
+			}
+		} else {
+			img, err = favicon.Embedded(svg)
+		}
+		if err != nil {
+			log.Error("Failed to read a raster version of the SVG, pass one with --png", slog.String("file", source), slog.String("error", err.Error()))
+			return 1
+		}
+	} else if img, err = favicon.Decode(b); err != nil {
+		log.Error("Failed to decode source image", slog.String("file", source), slog.String("error", err.Error()))
+		return 1
+	}
+	if size := img.Bounds(); min(size.Dx(), size.Dy()) < favicon.MinSize {
+		log.Warn("Source image is smaller than the largest icon and will be enlarged", slog.Int("width", size.Dx()), slog.Int("height", size.Dy()), slog.Int("want", favicon.MinSize))
+	}
+
+	colorsFile := filepath.Join(filepath.Dir(cfg.CSS.Input), "colors.css")
+	css, err := os.ReadFile(cfg.Path(colorsFile))
+	if err != nil {
+		log.Error("Failed to read the palette, run hgmx palette first", slog.String("file", colorsFile), slog.String("error", err.Error()))
+		return 1
+	}
+	shade, err := palette.ShadeFromCSS(string(css), palette.Base, fc.Shade)
+	if err != nil {
+		log.Error("Failed to read the base shade", slog.String("file", colorsFile), slog.String("error", err.Error()))
+		return 1
+	}
+	bg := palette.OklchToHex(&shade)
+
+	files, err := favicon.Generate(img, svg, shade)
+	if err != nil {
+		log.Error("Failed to render icons", slog.String("error", err.Error()))
+		return 1
+	}
+	dir := cfg.Path(fc.Dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Error("Failed to create favicon directory", slog.String("dir", dir), slog.String("error", err.Error()))
+		return 1
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f.Name), f.Data, 0o644); err != nil {
+			log.Error("Failed to write icon", slog.String("file", f.Name), slog.String("error", err.Error()))
+			return 1
+		}
+		log.Debug("Icon written", slog.String("file", f.Name), slog.Int("bytes", len(f.Data)))
+	}
+
+	m, err := favicon.LoadManifest(os.DirFS(dir), favicon.ManifestName)
+	if err != nil {
+		log.Error("Failed to read web app manifest", slog.String("error", err.Error()))
+		return 1
+	}
+	m.Name, m.ShortName = fc.Name, fc.ShortName
+	m.Icons = favicon.ManifestIcons
+	m.ThemeColor, m.BackgroundColor = bg, bg
+	manifest, err := m.Marshal()
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, favicon.ManifestName), manifest, 0o644)
+	}
+	if err != nil {
+		log.Error("Failed to write web app manifest", slog.String("error", err.Error()))
+		return 1
+	}
+
+	log.Info("Favicons written", slog.String("dir", fc.Dir), slog.Int("files", len(files)+1), slog.String("name", fc.Name), slog.String("color", bg))
+	return 0
+}
+
+// moduleName returns the last element of the module path in root's go.mod,
+// or the directory name without one.
+func moduleName(root string) string {
+	if b, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			if f := strings.Fields(line); len(f) == 2 && f[0] == "module" {
+				return path.Base(strings.Trim(f[1], `"`))
+			}
+		}
+	}
+	abs, _ := filepath.Abs(root)
+	return filepath.Base(abs)
+}
+
+// --- vendor command ---
+
+func vendorListCmd() (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, dir, m, err := loadVendor()
+	if err != nil {
+		log.Error("Failed to read vendor manifest", slog.String("error", err.Error()))
+		return 1
+	}
+
+	modified := 0
+	tracked := make(map[string]bool)
+	for _, l := range m.Libraries {
+		tracked[l.File] = true
+		status := "ok"
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(l.File)))
+		switch {
+		case err != nil:
+			status = "missing"
+		case scripts.Integrity(b) != l.Integrity:
+			status = "modified"
+		}
+		if status != "ok" {
+			modified++
+		}
+		log.Info(l.Name, slog.String("version", l.Version), slog.String("file", l.File), slog.String("status", status), slog.String("integrity", l.Integrity))
+	}
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".js" {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if !tracked[filepath.ToSlash(rel)] {
+			log.Warn("Untracked vendored file, replace it with hgmx vendor add", slog.String("file", filepath.ToSlash(rel)))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Error("Failed to list vendor directory", slog.String("dir", cfg.Vendor.Dir), slog.String("error", err.Error()))
+		return 1
+	}
+	if modified > 0 {
+		log.Warn("Some vendored files do not match their recorded hash, run hgmx vendor update", slog.Int("files", modified))
+		return 1
+	}
+	return 0
+}
+
+func vendorAddCmd(args []string) (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, dir, m, err := loadVendor()
+	if err != nil {
+		log.Error("Failed to read vendor manifest", slog.String("error", err.Error()))
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	f := scripts.NewFetcher()
+	var downloads []vendorDownload
+	for _, spec := range args {
+		name, version, err := scripts.ParseSpec(spec)
+		if err != nil {
+			log.Error("Invalid library", slog.String("error", err.Error()))
+			return 64
+		}
+		d, err := vendorFetch(ctx, f, name, version)
+		if err != nil {
+			log.Error("Failed to vendor library", slog.String("library", spec), slog.String("error", err.Error()))
+			return 1
+		}
+		downloads = append(downloads, d)
+	}
+	return writeVendor(log, cfg, dir, m, downloads)
+}
+
+func vendorUpdateCmd(args []string) (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, dir, m, err := loadVendor()
+	if err != nil {
+		log.Error("Failed to read vendor manifest", slog.String("error", err.Error()))
+		return 1
+	}
+	if len(args) == 0 {
+		for _, l := range m.Libraries {
+			args = append(args, l.Name)
+		}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	f := scripts.NewFetcher()
+	var downloads []vendorDownload
+	for _, spec := range args {
+		name, version, err := scripts.ParseSpec(spec)
+		if err != nil {
+			log.Error("Invalid library", slog.String("error", err.Error()))
+			return 64
+		}
+		current, ok := m.Get(name)
+		if !ok {
+			log.Error("Library is not vendored, use hgmx vendor add", slog.String("library", name))
+			return 1
+		}
+		if version == "" {
+			if version, err = f.Latest(ctx, name); err != nil {
+				log.Error("Failed to resolve latest version", slog.String("library", name), slog.String("error", err.Error()))
+				return 1
+			}
+		}
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(current.File)))
+		if err == nil && current.Version == version && scripts.Integrity(b) == current.Integrity {
+			log.Info("Library up to date", slog.String("library", name), slog.String("version", version))
+			continue
+		}
+		d, err := vendorFetch(ctx, f, name, version)
+		if err != nil {
+			log.Error("Failed to update library", slog.String("library", name), slog.String("error", err.Error()))
+			return 1
+		}
+		downloads = append(downloads, d)
+	}
+	return writeVendor(log, cfg, dir, m, downloads)
+}
+
+// loadVendor reads the project config and the vendor manifest.
+func loadVendor() (*project.Config, string, *scripts.Manifest, error) {
+	cfg, err := project.Load(".")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	dir := cfg.Path(cfg.Vendor.Dir)
+	m, err := scripts.Load(os.DirFS(dir), scripts.ManifestName)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return cfg, dir, m, nil
+}
+
+// vendorDownload is a fetched library not yet written to the vendor directory.
+type vendorDownload struct {
+	lib  scripts.Library
+	data []byte
+}
+
+// vendorFetch downloads version of name, the latest when empty. Nothing is
+// written until every library of the command has been fetched.
+func vendorFetch(ctx context.Context, f *scripts.Fetcher, name, version string) (vendorDownload, error) {
+	if version == "" {
+		latest, err := f.Latest(ctx, name)
+		if err != nil {
+			return vendorDownload{}, err
+		}
+		version = latest
+	}
+	l, b, err := f.Fetch(ctx, name, version)
+	if err != nil {
+		return vendorDownload{}, err
+	}
+	return vendorDownload{lib: l, data: b}, nil
+}
+
+// writeVendor writes the downloaded files into dir, records them in m and
+// writes the manifest, so the files and their integrity hashes change together.
+func writeVendor(log *slog.Logger, cfg *project.Config, dir string, m *scripts.Manifest, downloads []vendorDownload) (code int) {
+	for _, d := range downloads {
+		file := filepath.Join(dir, filepath.FromSlash(d.lib.File))
+		err := os.MkdirAll(filepath.Dir(file), 0o755)
+		if err == nil {
+			err = os.WriteFile(file, d.data, 0o644)
+		}
+		if err != nil {
+			log.Error("Failed to write library", slog.String("library", d.lib.Name), slog.String("file", d.lib.File), slog.String("error", err.Error()))
+			// record what was written so far, whose files are already replaced
+			writeVendorManifest(log, cfg, dir, m)
+			return 1
+		}
+		previous, _ := m.Get(d.lib.Name)
+		m.Add(d.lib)
+		log.Info("Library vendored", slog.String("library", d.lib.Name), slog.String("version", d.lib.Version), slog.String("previous", previous.Version), slog.String("file", d.lib.File), slog.Int("bytes", len(d.data)))
+	}
+	return writeVendorManifest(log, cfg, dir, m)
+}
+
+func writeVendorManifest(log *slog.Logger, cfg *project.Config, dir string, m *scripts.Manifest) (code int) {
+	b, err := m.Marshal()
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, scripts.ManifestName), b, 0o644)
+	}
+	if err != nil {
+		log.Error("Failed to write vendor manifest", slog.String("error", err.Error()))
+		return 1
+	}
+	log.Debug("Vendor manifest written", slog.String("file", filepath.Join(cfg.Vendor.Dir, scripts.ManifestName)))
+	return 0
+}
+
+// --- dev command ---
+
+func devCmd() (code int) {
+	log := newLogger(logLevel, os.Stderr)
+
+	cfg, err := project.Load(".")
+	if err != nil {
+		log.Error("Failed to read project config", slog.String("error", err.Error()))
+		return 1
+	}
+	_, port, err := net.SplitHostPort(cfg.Dev.App)
+	if err != nil {
+		log.Error("Invalid app address", slog.String("app", cfg.Dev.App), slog.String("error", err.Error()))
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	templBin, err := exec.LookPath("templ")
+	if err != nil {
+		log.Error("templ not found on PATH, install it with: go install github.com/a-h/templ/cmd/templ@latest")
+		return 1
+	}
+	generate := func(ctx context.Context, files []string) error {
+		for _, f := range files {
+			cmd := exec.CommandContext(ctx, templBin, "generate", "-f", f)
+			cmd.Stdout, cmd.Stderr = io.Discard, os.Stderr
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("%s: %w", f, err)
+			}
+			log.Debug("Generated", slog.String("file", f))
+		}
+		return nil
+	}
+	gen := exec.CommandContext(ctx, templBin, "generate", "-path", cfg.Path(cfg.Views))
+	gen.Stdout, gen.Stderr = io.Discard, os.Stderr
+	if err := gen.Run(); err != nil {
+		log.Error("templ generate failed", slog.String("error", err.Error()))
+	}
+
+	if err := regenerateSafelist(log, cfg); err != nil {
+		log.Warn("Failed to write safelist", slog.String("file", cfg.CSS.Safelist), slog.String("error", err.Error()))
+	}
+	if tw, err := tailwindCommand(ctx, log, cfg, true); err != nil {
+		log.Warn("CSS will not be rebuilt", slog.String("error", err.Error()))
+	} else if err := tw.Start(); err != nil {
+		log.Warn("CSS will not be rebuilt", slog.String("error", err.Error()))
+	} else {
+		defer tw.Wait()
+	}
+
+	tmp, err := os.MkdirTemp("", "hgmx-dev-")
+	if err != nil {
+		log.Error("Failed to create build directory", slog.String("error", err.Error()))
+		return 1
+	}
+	defer os.RemoveAll(tmp)
+
+	app := &dev.App{
+		Dir:     cfg.Root,
+		Package: cfg.Dev.Package,
+		Args:    cfg.Dev.Args,
+		Addr:    cfg.Dev.App,
+		Env:     []string{"PORT=" + port, "HGMX_DEV=1"},
+		Bin:     filepath.Join(tmp, "app"),
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+	}
+	defer app.Stop()
+	if err := app.Restart(ctx); err != nil {
+		log.Error("App failed to start, fix it and save to retry", slog.String("error", err.Error()))
+	}
+
+	reloader := dev.NewReloader(&url.URL{Scheme: "http", Host: cfg.Dev.App})
+	ln, err := net.Listen("tcp", cfg.Dev.Proxy)
+	if err != nil {
+		log.Error("Failed to listen", slog.String("addr", cfg.Dev.Proxy), slog.String("error", err.Error()))
+		return 1
+	}
+	srv := &http.Server{Handler: reloader}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	safelist := cfg.Path(cfg.CSS.Safelist)
+	events, err := dev.Watch(ctx, cfg.Root, func(p string) bool { return p == safelist })
+	if err != nil {
+		log.Error("Failed to watch project", slog.String("error", err.Error()))
+		return 1
+	}
+	log.Info("Dev server ready", slog.String("url", "http://"+cfg.Dev.Proxy), slog.String("app", cfg.Dev.App))
+
+	runner := &dev.Runner{
+		CSSOutput: cfg.Path(cfg.CSS.Output),
+		Log:       log,
+		Generate:  generate,
+		Safelist:  func() error { return regenerateSafelist(log, cfg) },
+		Restart:   app.Restart,
+		Reload:    reloader.Reload,
+	}
+	runner.Run(ctx, events)
+	return 0
+}
+
 // --- link command ---
 
 func linkCmd(inputGlob, outputGlob string) (code int) {
@@ -16,6 +16,26 @@ var rootCmd = &cobra.Command{
 var logLevel string
 var linkInput string
 var linkOutput string
+var paletteCheck bool
+var paletteEqualize bool
+var palettePreview bool
+var palettePNG string
+var paletteSVG string
+var paletteFormat string
+var paletteOutput string
+var paletteAlphas []int
+var buildInput string
+var buildOutput string
+var cssOffline bool
+var fontsFamily string
+var fontsRole string
+var fontsPreload bool
+var iconsForce bool
+var fontsSubsets []string
+var faviconName string
+var faviconShortName string
+var faviconShade int
+var faviconPNG string
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
@@ -23,11 +43,55 @@ func main() {
 	}
 }
 
+// exit ends the process with the code a command returned, unless it succeeded.
+func exit(code int) {
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Set log verbosity level [debug, info, warn, error]")
 	rootCmd.AddCommand(infoCobraCmd)
 	rootCmd.AddCommand(initCobraCmd)
 	rootCmd.AddCommand(paletteCobraCmd)
+	paletteCobraCmd.Flags().BoolVarP(&paletteCheck, "check", "c", false, "Report uneven or non-monotonic steps in each shade scale")
+	paletteCobraCmd.Flags().BoolVarP(&paletteEqualize, "equalize", "e", false, "Redistribute shades to equal perceptual spacing around the anchor shade")
+	paletteCobraCmd.Flags().BoolVarP(&palettePreview, "preview", "p", false, "Print every scale as terminal swatches instead of writing colors.css")
+	paletteCobraCmd.Flags().StringVar(&palettePNG, "png", "", "Render a labelled swatch grid to a PNG file instead of writing colors.css")
+	paletteCobraCmd.Flags().IntSliceVarP(&paletteAlphas, "alpha", "a", nil, "Opacity steps in percent to emit per shade, e.g. 10,20,50 (--base-600-a20)")
+	paletteCobraCmd.Flags().StringVarP(&paletteFormat, "format", "f", "css", "Output format [css, alacritty, kitty, wezterm, vscode, nvim]")
+	paletteCobraCmd.Flags().StringVarP(&paletteOutput, "output", "o", "", "Output file (default: library/static/css/colors.css for css, stdout otherwise)")
+	paletteCobraCmd.Flags().StringVar(&paletteSVG, "svg", "", "Render a labelled swatch grid to an SVG file instead of writing colors.css")
+	rootCmd.AddCommand(buildCobraCmd)
+	buildCobraCmd.Flags().StringVarP(&buildInput, "input", "i", "", "Static directory to fingerprint (default: build.static in hgmx.json, views/static)")
+	buildCobraCmd.Flags().StringVarP(&buildOutput, "output", "o", "", "Directory to write hashed files, .br/.gz siblings and manifest.json to (default: build.output in hgmx.json, dist/static)")
+	buildCobraCmd.Flags().BoolVar(&cssOffline, "offline", false, "Never download tailwindcss; fail if it is not on PATH or cached (also HGMX_OFFLINE=1)")
+	rootCmd.AddCommand(cssCobraCmd)
+	cssCobraCmd.PersistentFlags().BoolVar(&cssOffline, "offline", false, "Never download tailwindcss; fail if it is not on PATH or cached (also HGMX_OFFLINE=1)")
+	cssCobraCmd.AddCommand(cssBuildCobraCmd)
+	cssCobraCmd.AddCommand(cssWatchCobraCmd)
+	cssCobraCmd.AddCommand(cssCriticalCobraCmd)
+	rootCmd.AddCommand(fontsCobraCmd)
+	fontsCobraCmd.AddCommand(fontsAddCobraCmd)
+	fontsAddCobraCmd.Flags().StringVar(&fontsFamily, "family", "", "Family name to use instead of the one in the font")
+	fontsAddCobraCmd.Flags().StringVar(&fontsRole, "as", "", "Theme role to set to the family, written as --font-<role> [sans, serif, display, mono, ...]")
+	fontsAddCobraCmd.Flags().BoolVar(&fontsPreload, "preload", false, "Preload the added faces from the layout")
+	fontsAddCobraCmd.Flags().StringSliceVar(&fontsSubsets, "subset", nil, "Write one subset per unicode range, e.g. latin,latin-ext or name=U+0000-00FF (needs pyftsubset)")
+	rootCmd.AddCommand(iconsCobraCmd)
+	iconsCobraCmd.AddCommand(iconsAddCobraCmd)
+	iconsAddCobraCmd.Flags().BoolVar(&iconsForce, "force", false, "Replace icons that already exist")
+	rootCmd.AddCommand(faviconCobraCmd)
+	faviconCobraCmd.Flags().StringVar(&faviconName, "name", "", "App name for the web app manifest (default: favicon.name in hgmx.json, then the module name)")
+	faviconCobraCmd.Flags().StringVar(&faviconShortName, "short-name", "", "Short name shown under home screen icons (default: the name)")
+	faviconCobraCmd.Flags().IntVar(&faviconShade, "shade", 0, "Base shade for the theme and background colors (default: favicon.shade in hgmx.json, 600)")
+	faviconCobraCmd.Flags().StringVar(&faviconPNG, "png", "", "Raster version of an SVG source to render the PNG and ICO icons from")
+	rootCmd.AddCommand(vendorCobraCmd)
+	vendorCobraCmd.AddCommand(vendorListCobraCmd)
+	vendorCobraCmd.AddCommand(vendorAddCobraCmd)
+	vendorCobraCmd.AddCommand(vendorUpdateCobraCmd)
+	rootCmd.AddCommand(devCobraCmd)
+	devCobraCmd.Flags().BoolVar(&cssOffline, "offline", false, "Never download tailwindcss; skip CSS rebuilds if it is not on PATH or cached (also HGMX_OFFLINE=1)")
 	rootCmd.AddCommand(linkCobraCmd)
 	linkCobraCmd.Flags().StringVarP(&linkInput, "input", "i", "../hgmx/library/*", "Source directory to link from")
 	linkCobraCmd.Flags().StringVarP(&linkOutput, "output", "o", "views/*", "Transforms files in directory to symlinks")
@@ -37,7 +101,7 @@ var infoCobraCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Displays information about the hgmx environment",
 	Run: func(cmd *cobra.Command, args []string) {
-		infoCmd()
+		exit(infoCmd())
 	},
 }
 
@@ -45,7 +109,7 @@ var initCobraCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initializes a new hgmx project",
 	Run: func(cmd *cobra.Command, args []string) {
-		initCmd(args)
+		exit(initCmd(args))
 	},
 }
 
@@ -54,7 +118,119 @@ var paletteCobraCmd = &cobra.Command{
 	Short: "Generates a color palette based on the input hex color",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		paletteCmd(args)
+		exit(paletteCmd(args))
+	},
+}
+
+var buildCobraCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Generates templates and CSS, then fingerprints, precompresses, verifies and size checks static files",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(buildCmd(buildInput, buildOutput))
+	},
+}
+
+var cssCobraCmd = &cobra.Command{
+	Use:   "css",
+	Short: "Builds the project stylesheet with the standalone Tailwind CLI",
+}
+
+var cssBuildCobraCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Regenerates the safelist and builds the stylesheet once",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(cssCmd(false))
+	},
+}
+
+var cssWatchCobraCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Regenerates the safelist and rebuilds the stylesheet on every change",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(cssCmd(true))
+	},
+}
+
+var cssCriticalCobraCmd = &cobra.Command{
+	Use:   "critical",
+	Short: "Renders the configured pages and extracts the CSS they need above the fold",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(cssCriticalCmd())
+	},
+}
+
+var fontsCobraCmd = &cobra.Command{
+	Use:   "fonts",
+	Short: "Manages self-hosted fonts and their @font-face rules",
+}
+
+var fontsAddCobraCmd = &cobra.Command{
+	Use:   "add <file-or-dir>...",
+	Short: "Copies WOFF2 fonts into static and regenerates fonts.css",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(fontsAddCmd(args))
+	},
+}
+
+var iconsCobraCmd = &cobra.Command{
+	Use:   "icons",
+	Short: "Manages the SVG icon sprite and its Icon names",
+}
+
+var iconsAddCobraCmd = &cobra.Command{
+	Use:   "add <svg-or-dir>...",
+	Short: "Optimises SVG icons and rebuilds the sprite and icon constants",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(iconsAddCmd(args))
+	},
+}
+
+var faviconCobraCmd = &cobra.Command{
+	Use:   "favicon <source.png|svg>",
+	Short: "Renders the favicons, touch icons and web app manifest from one image",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(faviconCmd(args))
+	},
+}
+
+var vendorCobraCmd = &cobra.Command{
+	Use:   "vendor",
+	Short: "Manages the vendored JavaScript libraries and their integrity hashes",
+}
+
+var vendorListCobraCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists vendored libraries and checks their files against the recorded hashes",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(vendorListCmd())
+	},
+}
+
+var vendorAddCobraCmd = &cobra.Command{
+	Use:   "add <lib>[@<version>]...",
+	Short: "Vendors a library or htmx extension [htmx, hyperscript, motion, sse, ws, preload, response-targets]",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(vendorAddCmd(args))
+	},
+}
+
+var vendorUpdateCobraCmd = &cobra.Command{
+	Use:   "update [<lib>[@<version>]...]",
+	Short: "Updates vendored libraries to the latest or given version",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(vendorUpdateCmd(args))
+	},
+}
+
+var devCobraCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Runs the app behind a live reload proxy, regenerating templ and CSS on change",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit(devCmd())
 	},
 }
 
@@ -62,6 +238,6 @@ var linkCobraCmd = &cobra.Command{
 	Use:   "link",
 	Short: "Symlinks files in the output directory to the source directory",
 	Run: func(cmd *cobra.Command, args []string) {
-		linkCmd(linkInput, linkOutput)
+		exit(linkCmd(linkInput, linkOutput))
 	},
 }
@@ -0,0 +1,137 @@
+// Package csp sends a Content-Security-Policy with a fresh nonce per request.
+// The nonce is stored with templ.WithNonce, so views.Script, views.Style and
+// templ's own script components pick it up through templ.GetNonce.
+//
+// Under the default policy htmx and _hyperscript work without 'unsafe-eval',
+// except for the features that compile JavaScript at runtime:
+//
+//   - _hyperscript `_` attributes, `<script type="text/hyperscript">` blocks,
+//     behaviors and commands are interpreted and always work.
+//   - _hyperscript `js ... end` blocks and inline `js` expressions need
+//     'unsafe-eval' in script-src.
+//   - htmx hx-on:* attributes, `js:` values in hx-vals and hx-headers, and
+//     event filters such as hx-trigger="click[ctrlKey]" need 'unsafe-eval'.
+//   - <script> elements in swapped content are blocked. Setting
+//     views.InlineScriptNonce makes htmx stamp the request's nonce onto every
+//     swapped script, injected ones included, so it is only safe when all
+//     swapped HTML is trusted.
+package csp
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// Nonce is replaced by 'nonce-<value>' of the current request wherever it
+// appears in a Policy.
+const Nonce = "'nonce'"
+
+// Policy maps directives such as "script-src" to their sources.
+type Policy map[string][]string
+
+// DefaultPolicy returns a strict policy: same origin resources, including the
+// fonts self hosted with hgmx fonts, plus nonced scripts and styles.
+func DefaultPolicy() Policy {
+	return Policy{
+		"default-src":     {"'self'"},
+		"script-src":      {"'self'", Nonce},
+		"style-src":       {"'self'", Nonce},
+		"font-src":        {"'self'"},
+		"img-src":         {"'self'", "data:"},
+		"connect-src":     {"'self'"},
+		"object-src":      {"'none'"},
+		"base-uri":        {"'self'"},
+		"form-action":     {"'self'"},
+		"frame-ancestors": {"'none'"},
+	}
+}
+
+// With returns a copy of p with sources appended to directive.
+//
+//	csp.DefaultPolicy().With("script-src", "'unsafe-eval'")
+func (p Policy) With(directive string, sources ...string) Policy {
+	out := make(Policy, len(p)+1)
+	for d, s := range p {
+		out[d] = slices.Clone(s)
+	}
+	out[directive] = append(out[directive], sources...)
+	return out
+}
+
+// String formats p as a header value with nonce in place of Nonce. Directives
+// are sorted so the header is stable.
+func (p Policy) String(nonce string) string {
+	directives := make([]string, 0, len(p))
+	for d := range p {
+		directives = append(directives, d)
+	}
+	slices.Sort(directives)
+
+	var sb strings.Builder
+	for i, d := range directives {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(d)
+		for _, s := range p[d] {
+			if s == Nonce {
+				s = "'nonce-" + nonce + "'"
+			}
+			sb.WriteString(" " + s)
+		}
+	}
+	return sb.String()
+}
+
+// Option configures Protect.
+type Option func(*config)
+
+type config struct {
+	policy     Policy
+	reportOnly bool
+}
+
+// WithPolicy replaces DefaultPolicy.
+func WithPolicy(p Policy) Option {
+	return func(c *config) { c.policy = p }
+}
+
+// ReportOnly sends Content-Security-Policy-Report-Only instead, so violations
+// are reported but not blocked while a policy is tried out.
+func ReportOnly() Option {
+	return func(c *config) { c.reportOnly = true }
+}
+
+// Protect returns middleware that generates a nonce for every request, stores
+// it in the request context and sends the policy with it.
+//
+//	http.ListenAndServe(":8080", csp.Protect()(mux))
+func Protect(opts ...Option) func(http.Handler) http.Handler {
+	c := &config{policy: DefaultPolicy()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	header := "Content-Security-Policy"
+	if c.reportOnly {
+		header = "Content-Security-Policy-Report-Only"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nonce := newNonce()
+			w.Header().Set(header, c.policy.String(nonce))
+			next.ServeHTTP(w, r.WithContext(templ.WithNonce(r.Context(), nonce)))
+		})
+	}
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawStdEncoding.EncodeToString(b)
+}
@@ -0,0 +1,59 @@
+package csp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestPolicyString(t *testing.T) {
+	p := Policy{"script-src": {"'self'", Nonce}, "default-src": {"'none'"}}
+	want := "default-src 'none'; script-src 'self' 'nonce-abc'"
+	if got := p.String("abc"); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	q := p.With("script-src", "'unsafe-eval'")
+	if got := len(p["script-src"]); got != 2 {
+		t.Errorf("With modified the original policy: %q", p["script-src"])
+	}
+	if got := q.String("abc"); !strings.Contains(got, "script-src 'self' 'nonce-abc' 'unsafe-eval'") {
+		t.Errorf("With() = %q", got)
+	}
+}
+
+func TestProtect(t *testing.T) {
+	var nonces []string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonces = append(nonces, templ.GetNonce(r.Context()))
+	})
+	h := Protect()(next)
+
+	var headers []string
+	for range 2 {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		headers = append(headers, rec.Header().Get("Content-Security-Policy"))
+	}
+
+	if nonces[0] == "" || nonces[0] == nonces[1] {
+		t.Fatalf("nonces = %q, want two different nonces", nonces)
+	}
+	for i, header := range headers {
+		if !strings.Contains(header, "script-src 'self' 'nonce-"+nonces[i]+"'") {
+			t.Errorf("header %d = %q, want the request nonce in script-src", i, header)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	Protect(ReportOnly(), WithPolicy(Policy{"default-src": {"'self'"}}))(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rec.Header().Get("Content-Security-Policy-Report-Only"); got != "default-src 'self'" {
+		t.Errorf("report only header = %q", got)
+	}
+	if got := rec.Header().Get("Content-Security-Policy"); got != "" {
+		t.Errorf("enforced header sent in report only mode: %q", got)
+	}
+}
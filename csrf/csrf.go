@@ -0,0 +1,178 @@
+// Package csrf protects unsafe requests with a per-session token. The token is
+// kept in a session cookie and must be echoed back in the X-CSRF-Token header,
+// which the layout sets on every htmx request through hx-headers, or in the
+// csrf_token form field for plain form posts.
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// HeaderName is the request header carrying the token.
+	HeaderName = "X-CSRF-Token"
+	// FieldName is the form field carrying the token.
+	FieldName = "csrf_token"
+)
+
+const tokenLength = 32
+
+// Option configures Protect.
+type Option func(*config)
+
+type config struct {
+	cookie  string
+	secure  *bool
+	failure http.Handler
+}
+
+// WithCookieName sets the name of the cookie holding the session token.
+func WithCookieName(name string) Option {
+	return func(c *config) { c.cookie = name }
+}
+
+// WithSecure forces the Secure attribute of the cookie on or off. By default
+// it is set for requests received over TLS.
+func WithSecure(secure bool) Option {
+	return func(c *config) { c.secure = &secure }
+}
+
+// WithFailureHandler sets the handler for requests with a missing or wrong
+// token. The default responds 403 Forbidden.
+func WithFailureHandler(h http.Handler) Option {
+	return func(c *config) { c.failure = h }
+}
+
+type contextKey struct{}
+
+// Protect returns middleware issuing a token to every client that has none and
+// rejecting POST, PUT, PATCH and DELETE requests, or any other unsafe method,
+// whose header or form field does not match it. Handlers read the token to
+// render with Token.
+//
+//	http.ListenAndServe(":8080", csrf.Protect()(mux))
+func Protect(opts ...Option) func(http.Handler) http.Handler {
+	c := &config{
+		cookie:  "csrf",
+		failure: http.HandlerFunc(forbidden),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := c.read(r)
+			if token == nil {
+				token = make([]byte, tokenLength)
+				rand.Read(token)
+				c.write(w, r, token)
+			}
+
+			if !safe(r.Method) && !valid(token, submitted(r)) {
+				c.failure.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, token)))
+		})
+	}
+}
+
+// Token returns a token for the current request to embed in a page, or "" when
+// the request did not pass through Protect. Every call returns a differently
+// masked token, so it cannot be recovered by compression side channels.
+func Token(ctx context.Context) string {
+	token, _ := ctx.Value(contextKey{}).([]byte)
+	if token == nil {
+		return ""
+	}
+	return mask(token)
+}
+
+// HXHeaders returns the value of an hx-headers attribute sending the token
+// with every htmx request, or "" when there is no token.
+func HXHeaders(ctx context.Context) string {
+	token := Token(ctx)
+	if token == "" {
+		return ""
+	}
+	b, _ := json.Marshal(map[string]string{HeaderName: token})
+	return string(b)
+}
+
+func (c *config) read(r *http.Request) []byte {
+	cookie, err := r.Cookie(c.cookie)
+	if err != nil {
+		return nil
+	}
+	token, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(token) != tokenLength {
+		return nil
+	}
+	return token
+}
+
+func (c *config) write(w http.ResponseWriter, r *http.Request, token []byte) {
+	secure := r.TLS != nil
+	if c.secure != nil {
+		secure = *c.secure
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.cookie,
+		Value:    base64.RawURLEncoding.EncodeToString(token),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.Header().Add("Vary", "Cookie")
+}
+
+// submitted returns the token sent with r, from the header or else the form.
+func submitted(r *http.Request) string {
+	if token := r.Header.Get(HeaderName); token != "" {
+		return token
+	}
+	return r.PostFormValue(FieldName)
+}
+
+// valid reports whether masked, as returned by Token, unmasks to token.
+func valid(token []byte, masked string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(masked)
+	if err != nil || len(b) != 2*tokenLength {
+		return false
+	}
+	pad, sealed := b[:tokenLength], b[tokenLength:]
+	for i := range sealed {
+		sealed[i] ^= pad[i]
+	}
+	return subtle.ConstantTimeCompare(sealed, token) == 1
+}
+
+// mask returns a random pad followed by token XORed with the pad.
+func mask(token []byte) string {
+	b := make([]byte, 2*tokenLength)
+	rand.Read(b[:tokenLength])
+	for i := range token {
+		b[tokenLength+i] = b[i] ^ token[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func safe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func forbidden(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "invalid CSRF token", http.StatusForbidden)
+}
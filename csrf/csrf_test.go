@@ -0,0 +1,92 @@
+package csrf
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// issue performs a GET through h and returns the session cookie and a token
+// rendered for the page.
+func issue(t *testing.T, h http.Handler) (*http.Cookie, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %v, want one HttpOnly cookie", cookies)
+	}
+	return cookies[0], rec.Body.String()
+}
+
+func TestProtect(t *testing.T) {
+	h := Protect()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, Token(r.Context()))
+	}))
+	cookie, token := issue(t, h)
+	_, other := issue(t, h)
+
+	tests := []struct {
+		name   string
+		method string
+		header string
+		form   string
+		cookie bool
+		want   int
+	}{
+		{"get", http.MethodGet, "", "", false, http.StatusOK},
+		{"header", http.MethodPost, token, "", true, http.StatusOK},
+		{"form field", http.MethodPost, "", token, true, http.StatusOK},
+		{"delete", http.MethodDelete, token, "", true, http.StatusOK},
+		{"missing", http.MethodPost, "", "", true, http.StatusForbidden},
+		{"no cookie", http.MethodPost, token, "", false, http.StatusForbidden},
+		{"other session", http.MethodPost, other, "", true, http.StatusForbidden},
+		{"garbage", http.MethodPut, "not-a-token", "", true, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := url.Values{}
+			if tt.form != "" {
+				body.Set(FieldName, tt.form)
+			}
+			r := httptest.NewRequest(tt.method, "/", strings.NewReader(body.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.header != "" {
+				r.Header.Set(HeaderName, tt.header)
+			}
+			if tt.cookie {
+				r.AddCookie(cookie)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenMasked(t *testing.T) {
+	var first, second, headers string
+	h := Protect()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		first, second = Token(r.Context()), Token(r.Context())
+		headers = HXHeaders(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if first == "" || first == second {
+		t.Errorf("Token() = %q then %q, want two different masked tokens", first, second)
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(headers), &m); err != nil || m[HeaderName] == "" {
+		t.Errorf("HXHeaders() = %q", headers)
+	}
+
+	if got := Token(httptest.NewRequest(http.MethodGet, "/", nil).Context()); got != "" {
+		t.Errorf("Token() without Protect = %q, want empty", got)
+	}
+}
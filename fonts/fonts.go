@@ -0,0 +1,192 @@
+// Package fonts self-hosts web fonts. It reads the family, style, weight and
+// variation axes of WOFF2 files, keeps a registry of the faces in the static
+// fonts directory, and writes the @font-face rules and Tailwind --font-* theme
+// variables generated from it. The layout reads the same registry to preload
+// the faces marked for it.
+package fonts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RegistryName is the registry file kept next to the fonts.
+const RegistryName = "fonts.json"
+
+// Registry lists the self-hosted faces and the theme roles they fill.
+type Registry struct {
+	Faces []Face `json:"faces"`
+	// Theme maps a role such as "sans" or "mono" to a family, written as
+	// --font-<role>.
+	Theme map[string]string `json:"theme,omitempty"`
+}
+
+// Face is one @font-face rule. Style, Weight and Stretch hold CSS values,
+// ranges for variable fonts.
+type Face struct {
+	Family       string `json:"family"`
+	File         string `json:"file"` // relative to the fonts directory
+	Style        string `json:"style"`
+	Weight       string `json:"weight"`
+	Stretch      string `json:"stretch,omitempty"`
+	UnicodeRange string `json:"unicode_range,omitempty"`
+	Axes         []Axis `json:"axes,omitempty"`
+	Preload      bool   `json:"preload,omitempty"`
+}
+
+// NewFace describes file from its parsed info.
+func NewFace(file string, info *Info) Face {
+	f := Face{
+		Family: info.Family,
+		File:   file,
+		Style:  "normal",
+		Weight: cssRange(info.Weight, ""),
+		Axes:   info.Axes,
+	}
+	switch {
+	case info.Italic:
+		f.Style = "italic"
+	case info.Slant != [2]float64{}:
+		// slnt is counter-clockwise, oblique angles are clockwise. Adding
+		// zero turns -0 into 0.
+		f.Style = "oblique " + cssRange([2]float64{-info.Slant[1] + 0, -info.Slant[0] + 0}, "deg")
+	}
+	if info.Stretch != [2]float64{100, 100} {
+		f.Stretch = cssRange(info.Stretch, "%")
+	}
+	return f
+}
+
+// cssRange formats a min and max, collapsing equal values.
+func cssRange(r [2]float64, unit string) string {
+	lo := strconv.FormatFloat(r[0], 'f', -1, 64) + unit
+	if r[0] == r[1] {
+		return lo
+	}
+	return lo + " " + strconv.FormatFloat(r[1], 'f', -1, 64) + unit
+}
+
+// Load reads the registry name from fsys. A missing registry is empty.
+func Load(fsys fs.FS, name string) (*Registry, error) {
+	r := &Registry{}
+	b, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return r, nil
+}
+
+// Add adds f, replacing a face with the same file.
+func (r *Registry) Add(f Face) {
+	if i := slices.IndexFunc(r.Faces, func(g Face) bool { return g.File == f.File }); i >= 0 {
+		r.Faces[i] = f
+		return
+	}
+	r.Faces = append(r.Faces, f)
+}
+
+// SetRole makes family the theme font for role.
+func (r *Registry) SetRole(role, family string) {
+	if r.Theme == nil {
+		r.Theme = make(map[string]string)
+	}
+	r.Theme[role] = family
+}
+
+// Marshal encodes the registry for writing back to disk.
+func (r *Registry) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(r, "", "  ")
+	return append(b, '\n'), err
+}
+
+// Preloads returns the files of the faces marked for preloading.
+func (r *Registry) Preloads() []string {
+	var files []string
+	for _, f := range r.Faces {
+		if f.Preload {
+			files = append(files, f.File)
+		}
+	}
+	return files
+}
+
+// fallbacks are the generic families appended to each theme role.
+var fallbacks = map[string]string{
+	"sans":    "sans-serif",
+	"serif":   "serif",
+	"mono":    "monospace",
+	"display": "sans-serif",
+	"script":  "cursive",
+}
+
+// roleOrder lists the usual roles first in the @theme block.
+var roleOrder = []string{"sans", "serif", "display", "mono", "script"}
+
+// WriteCSS writes a @font-face rule per face and a @theme block with the
+// --font-* variables. url is the fonts directory relative to the stylesheet,
+// e.g. "../fonts".
+func (r *Registry) WriteCSS(w io.Writer, url string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "/* Generated by hgmx fonts from %s, do not edit. */\n", path.Join(url, RegistryName))
+	for _, f := range r.Faces {
+		sb.WriteString("\n@font-face {\n")
+		fmt.Fprintf(&sb, "  font-family: %q;\n", f.Family)
+		fmt.Fprintf(&sb, "  font-style: %s;\n", f.Style)
+		fmt.Fprintf(&sb, "  font-weight: %s;\n", f.Weight)
+		if f.Stretch != "" {
+			fmt.Fprintf(&sb, "  font-stretch: %s;\n", f.Stretch)
+		}
+		sb.WriteString("  font-display: swap;\n")
+		fmt.Fprintf(&sb, "  src: url(%q) format(\"woff2\");\n", path.Join(url, f.File))
+		if f.UnicodeRange != "" {
+			fmt.Fprintf(&sb, "  unicode-range: %s;\n", f.UnicodeRange)
+		}
+		sb.WriteString("}\n")
+	}
+
+	if len(r.Theme) > 0 {
+		roles := make([]string, 0, len(r.Theme))
+		for role := range r.Theme {
+			roles = append(roles, role)
+		}
+		slices.SortFunc(roles, func(a, b string) int {
+			ia, ib := slices.Index(roleOrder, a), slices.Index(roleOrder, b)
+			if ia < 0 {
+				ia = len(roleOrder)
+			}
+			if ib < 0 {
+				ib = len(roleOrder)
+			}
+			if ia != ib {
+				return ia - ib
+			}
+			return strings.Compare(a, b)
+		})
+
+		sb.WriteString("\n@theme {\n")
+		for _, role := range roles {
+			fallback, ok := fallbacks[role]
+			if !ok {
+				fallback = "sans-serif"
+			}
+			fmt.Fprintf(&sb, "  --font-%s: %q, %s;\n", role, r.Theme[role], fallback)
+		}
+		sb.WriteString("}\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
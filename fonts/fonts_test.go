@@ -0,0 +1,241 @@
+package fonts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"unicode/utf16"
+
+	"github.com/andybalholm/brotli"
+)
+
+// table is a named sfnt table.
+type table struct {
+	tag  string
+	data []byte
+}
+
+func u16(v int) []byte { return binary.BigEndian.AppendUint16(nil, uint16(v)) }
+
+// testTables returns the name, OS/2 and, when variable, fvar tables of a font.
+func testTables(family string, weight int, italic, variable bool) []table {
+	name16 := utf16.Encode([]rune(family))
+	str := make([]byte, 0, 2*len(name16))
+	for _, c := range name16 {
+		str = binary.BigEndian.AppendUint16(str, c)
+	}
+	var name []byte
+	for _, v := range []int{0, 1, 6 + 12} { // format, count, storage offset
+		name = append(name, u16(v)...)
+	}
+	for _, v := range []int{3, 1, 0x409, 16, len(str), 0} {
+		name = append(name, u16(v)...)
+	}
+	name = append(name, str...)
+
+	os2 := make([]byte, 96)
+	binary.BigEndian.PutUint16(os2[4:], uint16(weight))
+	binary.BigEndian.PutUint16(os2[6:], 5)
+	if italic {
+		binary.BigEndian.PutUint16(os2[62:], 1)
+	}
+	tables := []table{{"name", name}, {"OS/2", os2}}
+
+	if variable {
+		fvar := make([]byte, 16)
+		binary.BigEndian.PutUint16(fvar[0:], 1)
+		binary.BigEndian.PutUint16(fvar[4:], 16)
+		binary.BigEndian.PutUint16(fvar[8:], 2)
+		binary.BigEndian.PutUint16(fvar[10:], 20)
+		for _, a := range []struct {
+			tag           string
+			min, def, max int32
+		}{{"wght", 100, 400, 900}, {"wdth", 75, 100, 100}} {
+			rec := []byte(a.tag)
+			for _, v := range []int32{a.min, a.def, a.max} {
+				rec = binary.BigEndian.AppendUint32(rec, uint32(v<<16))
+			}
+			fvar = append(fvar, append(rec, 0, 0, 0, 0)...)
+		}
+		tables = append(tables, table{"fvar", fvar})
+	}
+	return tables
+}
+
+// sfnt assembles a TrueType file.
+func sfnt(tables []table) []byte {
+	out := binary.BigEndian.AppendUint32(nil, 0x00010000)
+	out = append(out, u16(len(tables))...)
+	out = append(out, make([]byte, 6)...)
+	offset := 12 + 16*len(tables)
+	var data []byte
+	for _, t := range tables {
+		out = append(out, t.tag...)
+		out = binary.BigEndian.AppendUint32(out, 0)
+		out = binary.BigEndian.AppendUint32(out, uint32(offset+len(data)))
+		out = binary.BigEndian.AppendUint32(out, uint32(len(t.data)))
+		data = append(data, t.data...)
+	}
+	return append(out, data...)
+}
+
+// woff2 assembles a WOFF2 file with untransformed tables, naming name and OS/2
+// by index and fvar by tag.
+func woff2(t *testing.T, tables []table) []byte {
+	var dir, stream []byte
+	for _, tb := range tables {
+		switch tb.tag {
+		case "name":
+			dir = append(dir, 5)
+		case "OS/2":
+			dir = append(dir, 6)
+		default:
+			dir = append(append(dir, 0x3f), tb.tag...)
+		}
+		dir = appendBase128(dir, uint32(len(tb.data)))
+		stream = append(stream, tb.data...)
+	}
+	var compressed bytes.Buffer
+	w := brotli.NewWriter(&compressed)
+	w.Write(stream)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	header := make([]byte, 48)
+	copy(header, "wOF2")
+	binary.BigEndian.PutUint32(header[4:], 0x00010000)
+	binary.BigEndian.PutUint16(header[12:], uint16(len(tables)))
+	binary.BigEndian.PutUint32(header[20:], uint32(compressed.Len()))
+	return append(append(header, dir...), compressed.Bytes()...)
+}
+
+func appendBase128(b []byte, v uint32) []byte {
+	var digits []byte
+	for {
+		digits = append([]byte{byte(v & 0x7f)}, digits...)
+		if v >>= 7; v == 0 {
+			break
+		}
+	}
+	for i := range digits[:len(digits)-1] {
+		digits[i] |= 0x80
+	}
+	return append(b, digits...)
+}
+
+func TestParse(t *testing.T) {
+	variable := testTables("Test Sans Variable", 400, true, true)
+	want := &Info{
+		Family:  "Test Sans",
+		Italic:  true,
+		Weight:  [2]float64{100, 900},
+		Stretch: [2]float64{75, 100},
+		Axes:    []Axis{{"wght", 100, 400, 900}, {"wdth", 75, 100, 100}},
+	}
+	for format, data := range map[string][]byte{"sfnt": sfnt(variable), "woff2": woff2(t, variable)} {
+		info, err := Parse(data)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !reflect.DeepEqual(info, want) {
+			t.Errorf("%s: Parse() = %+v, want %+v", format, info, want)
+		}
+	}
+
+	info, err := Parse(woff2(t, testTables("Test Mono", 700, false, false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Family != "Test Mono" || info.Italic || info.Variable() || info.Weight != [2]float64{700, 700} {
+		t.Errorf("Parse() of a static font = %+v", info)
+	}
+
+	if _, err := Parse([]byte("not a font at all")); err == nil {
+		t.Error("Parse() of garbage succeeded")
+	}
+}
+
+func TestWriteCSS(t *testing.T) {
+	reg, err := Load(fstest.MapFS{}, RegistryName)
+	if err != nil || len(reg.Faces) != 0 {
+		t.Fatalf("Load() without a registry = %+v, %v", reg, err)
+	}
+
+	sans := NewFace("Sans.woff2", &Info{Family: "Sans", Weight: [2]float64{100, 900}, Stretch: [2]float64{75, 100}, Slant: [2]float64{-10, 0}})
+	sans.UnicodeRange = Subsets["greek-ext"]
+	reg.Add(sans)
+	reg.Add(NewFace("Mono.woff2", &Info{Family: "Mono", Weight: [2]float64{400, 400}, Stretch: [2]float64{100, 100}}))
+	mono := reg.Faces[1]
+	mono.Preload = true
+	reg.Add(mono)
+	reg.SetRole("mono", "Mono")
+	reg.SetRole("sans", "Sans")
+	reg.SetRole("hand", "Sans")
+
+	if len(reg.Faces) != 2 || !reflect.DeepEqual(reg.Preloads(), []string{"Mono.woff2"}) {
+		t.Errorf("Add() did not replace the face: %+v", reg.Faces)
+	}
+
+	var sb strings.Builder
+	if err := reg.WriteCSS(&sb, "../fonts"); err != nil {
+		t.Fatal(err)
+	}
+	want := `/* Generated by hgmx fonts from ../fonts/fonts.json, do not edit. */
+
+@font-face {
+  font-family: "Sans";
+  font-style: oblique 0deg 10deg;
+  font-weight: 100 900;
+  font-stretch: 75% 100%;
+  font-display: swap;
+  src: url("../fonts/Sans.woff2") format("woff2");
+  unicode-range: U+1F00-1FFF;
+}
+
+@font-face {
+  font-family: "Mono";
+  font-style: normal;
+  font-weight: 400;
+  font-display: swap;
+  src: url("../fonts/Mono.woff2") format("woff2");
+}
+
+@theme {
+  --font-sans: "Sans", sans-serif;
+  --font-mono: "Mono", monospace;
+  --font-hand: "Sans", sans-serif;
+}
+`
+	if sb.String() != want {
+		t.Errorf("WriteCSS() =\n%s\nwant\n%s", sb.String(), want)
+	}
+
+	b, err := reg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(fstest.MapFS{RegistryName: {Data: b}}, RegistryName)
+	if err != nil || !reflect.DeepEqual(loaded, reg) {
+		t.Errorf("Load(Marshal()) = %+v, %v, want %+v", loaded, err, reg)
+	}
+}
+
+func TestParseSubset(t *testing.T) {
+	s, err := ParseSubset("latin")
+	if err != nil || s.Range != Subsets["latin"] || s.File("fonts/Sans.woff2") != "fonts/Sans.latin.woff2" {
+		t.Errorf("ParseSubset(latin) = %+v, %v", s, err)
+	}
+	s, err = ParseSubset("arrows=U+2190-21FF,U+27F0-27FF")
+	if err != nil || s.Range != "U+2190-21FF, U+27F0-27FF" {
+		t.Errorf("ParseSubset(custom) = %+v, %v", s, err)
+	}
+	for _, spec := range []string{"klingon", "x=U+ZZ", "../x=U+0041", "x="} {
+		if _, err := ParseSubset(spec); err == nil {
+			t.Errorf("ParseSubset(%q) succeeded", spec)
+		}
+	}
+}
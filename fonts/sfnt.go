@@ -0,0 +1,290 @@
+package fonts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Info is what a font file says about itself.
+type Info struct {
+	Family  string // typographic family name
+	Italic  bool
+	Weight  [2]float64 // min and max, equal for a static font
+	Stretch [2]float64 // percent of normal width, min and max
+	Slant   [2]float64 // slnt axis in degrees, zero for none
+	Axes    []Axis     // variation axes, nil for a static font
+}
+
+// Axis is a variation axis of a variable font.
+type Axis struct {
+	Tag     string  `json:"tag"`
+	Min     float64 `json:"min"`
+	Default float64 `json:"default"`
+	Max     float64 `json:"max"`
+}
+
+// Variable reports whether the font has variation axes.
+func (i *Info) Variable() bool { return len(i.Axes) > 0 }
+
+var errFormat = errors.New("not a WOFF2, TrueType or OpenType font")
+
+// Parse reads the name, OS/2 and fvar tables of a WOFF2 file, or of a plain
+// TrueType or OpenType file.
+func Parse(data []byte) (*Info, error) {
+	var tables map[string][]byte
+	var err error
+	switch {
+	case len(data) >= 4 && string(data[:4]) == "wOF2":
+		tables, err = woff2Tables(data)
+	case len(data) >= 4 && (string(data[:4]) == "OTTO" || string(data[:4]) == "true" || binary.BigEndian.Uint32(data) == 0x00010000):
+		tables, err = sfntTables(data)
+	default:
+		err = errFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{Weight: [2]float64{400, 400}, Stretch: [2]float64{100, 100}}
+	if os2 := tables["OS/2"]; len(os2) >= 64 {
+		weight := float64(binary.BigEndian.Uint16(os2[4:]))
+		info.Weight = [2]float64{weight, weight}
+		if width := int(binary.BigEndian.Uint16(os2[6:])); width >= 1 && width <= 9 {
+			stretch := widthClasses[width-1]
+			info.Stretch = [2]float64{stretch, stretch}
+		}
+		info.Italic = binary.BigEndian.Uint16(os2[62:])&1 != 0
+	} else if head := tables["head"]; len(head) >= 46 {
+		info.Italic = binary.BigEndian.Uint16(head[44:])&2 != 0
+	}
+
+	if info.Axes, err = parseFvar(tables["fvar"]); err != nil {
+		return nil, err
+	}
+	for _, a := range info.Axes {
+		switch a.Tag {
+		case "wght":
+			info.Weight = [2]float64{a.Min, a.Max}
+		case "wdth":
+			info.Stretch = [2]float64{a.Min, a.Max}
+		case "slnt":
+			info.Slant = [2]float64{a.Min, a.Max}
+		}
+	}
+
+	names := parseNames(tables["name"])
+	info.Family = names[16]
+	if info.Family == "" {
+		info.Family = names[1]
+	}
+	if info.Family == "" {
+		return nil, errors.New("font has no family name")
+	}
+	if info.Variable() {
+		// Legacy family names of variable fonts often carry the format.
+		info.Family = strings.TrimSuffix(info.Family, " Variable")
+	}
+	return info, nil
+}
+
+// widthClasses maps OS/2 usWidthClass 1 to 9 to font-stretch percentages.
+var widthClasses = [9]float64{50, 62.5, 75, 87.5, 100, 112.5, 125, 150, 200}
+
+// sfntTables slices the tables out of a TrueType or OpenType file.
+func sfntTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, errFormat
+	}
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*n {
+		return nil, errFormat
+	}
+	tables := make(map[string][]byte, n)
+	for i := range n {
+		rec := data[12+16*i:]
+		offset, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		if uint64(offset)+uint64(length) > uint64(len(data)) {
+			return nil, fmt.Errorf("table %q out of bounds", rec[:4])
+		}
+		tables[string(rec[:4])] = data[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// woff2Tags are the tags a WOFF2 table directory refers to by index.
+var woff2Tags = [63]string{
+	"cmap", "head", "hhea", "hmtx", "maxp", "name", "OS/2", "post", "cvt ", "fpgm",
+	"glyf", "loca", "prep", "CFF ", "VORG", "EBDT", "EBLC", "gasp", "hdmx", "kern",
+	"LTSH", "PCLT", "VDMX", "vhea", "vmtx", "BASE", "GDEF", "GPOS", "GSUB", "EBSC",
+	"JSTF", "MATH", "CBDT", "CBLC", "COLR", "CPAL", "SVG ", "sbix", "acnt", "avar",
+	"bdat", "bloc", "bsln", "cvar", "fdsc", "feat", "fmtx", "fvar", "gvar", "hsty",
+	"just", "lcar", "mort", "morx", "opbd", "prop", "trak", "Zapf", "Silf", "Glat",
+	"Gloc", "Feat", "Sill",
+}
+
+// woff2Tables decompresses a WOFF2 file and slices out its tables. The glyf,
+// loca and hmtx tables stay in their transformed form, which is fine for
+// reading metadata.
+func woff2Tables(data []byte) (map[string][]byte, error) {
+	if len(data) < 48 {
+		return nil, errFormat
+	}
+	if string(data[4:8]) == "ttcf" {
+		return nil, errors.New("WOFF2 font collections are not supported")
+	}
+	numTables := int(binary.BigEndian.Uint16(data[12:]))
+	compressedSize := binary.BigEndian.Uint32(data[20:])
+
+	type entry struct {
+		tag    string
+		length uint32
+	}
+	r := bytes.NewReader(data[48:])
+	entries := make([]entry, numTables)
+	for i := range entries {
+		flags, err := r.ReadByte()
+		if err != nil {
+			return nil, errFormat
+		}
+		tag := ""
+		if idx := flags & 0x3f; idx == 0x3f {
+			var b [4]byte
+			if _, err := io.ReadFull(r, b[:]); err != nil {
+				return nil, errFormat
+			}
+			tag = string(b[:])
+		} else {
+			tag = woff2Tags[idx]
+		}
+		length, err := base128(r)
+		if err != nil {
+			return nil, err
+		}
+		version := flags >> 6
+		transformed := version != 0
+		if tag == "glyf" || tag == "loca" {
+			transformed = version != 3
+		}
+		if transformed {
+			if length, err = base128(r); err != nil {
+				return nil, err
+			}
+		}
+		entries[i] = entry{tag, length}
+	}
+
+	start := len(data) - r.Len()
+	if uint64(start)+uint64(compressedSize) > uint64(len(data)) {
+		return nil, errFormat
+	}
+	stream, err := io.ReadAll(brotli.NewReader(bytes.NewReader(data[start : start+int(compressedSize)])))
+	if err != nil {
+		return nil, fmt.Errorf("decompress WOFF2 tables: %w", err)
+	}
+
+	tables := make(map[string][]byte, numTables)
+	var offset uint64
+	for _, e := range entries {
+		if offset+uint64(e.length) > uint64(len(stream)) {
+			return nil, fmt.Errorf("table %q out of bounds", e.tag)
+		}
+		tables[e.tag] = stream[offset : offset+uint64(e.length)]
+		offset += uint64(e.length)
+	}
+	return tables, nil
+}
+
+// base128 reads a WOFF2 UIntBase128.
+func base128(r io.ByteReader) (uint32, error) {
+	var v uint32
+	for i := range 5 {
+		b, err := r.ReadByte()
+		if err != nil || (i == 0 && b == 0x80) || v&0xfe000000 != 0 {
+			return 0, errors.New("invalid WOFF2 UIntBase128")
+		}
+		v = v<<7 | uint32(b&0x7f)
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, errors.New("invalid WOFF2 UIntBase128")
+}
+
+// parseFvar returns the axes of an fvar table.
+func parseFvar(fvar []byte) ([]Axis, error) {
+	if len(fvar) == 0 {
+		return nil, nil
+	}
+	if len(fvar) < 16 {
+		return nil, errors.New("truncated fvar table")
+	}
+	offset := int(binary.BigEndian.Uint16(fvar[4:]))
+	count := int(binary.BigEndian.Uint16(fvar[8:]))
+	size := int(binary.BigEndian.Uint16(fvar[10:]))
+	if size < 20 || offset+count*size > len(fvar) {
+		return nil, errors.New("truncated fvar table")
+	}
+	axes := make([]Axis, count)
+	for i := range axes {
+		rec := fvar[offset+i*size:]
+		axes[i] = Axis{
+			Tag:     string(rec[:4]),
+			Min:     fixed(rec[4:]),
+			Default: fixed(rec[8:]),
+			Max:     fixed(rec[12:]),
+		}
+	}
+	return axes, nil
+}
+
+// fixed decodes a 16.16 fixed point number, rounded to three decimals.
+func fixed(b []byte) float64 {
+	v := float64(int32(binary.BigEndian.Uint32(b))) / 65536
+	return math.Round(v*1000) / 1000
+}
+
+// parseNames returns the English names of a name table by name ID, preferring
+// Windows Unicode records over Macintosh Roman ones.
+func parseNames(name []byte) map[uint16]string {
+	names := make(map[uint16]string)
+	if len(name) < 6 {
+		return names
+	}
+	count := int(binary.BigEndian.Uint16(name[2:]))
+	storage := int(binary.BigEndian.Uint16(name[4:]))
+	for i := range count {
+		if 6+12*(i+1) > len(name) {
+			break
+		}
+		rec := name[6+12*i:]
+		platform := binary.BigEndian.Uint16(rec)
+		language := binary.BigEndian.Uint16(rec[4:])
+		id := binary.BigEndian.Uint16(rec[6:])
+		length, offset := int(binary.BigEndian.Uint16(rec[8:])), int(binary.BigEndian.Uint16(rec[10:]))
+		if storage+offset+length > len(name) {
+			continue
+		}
+		raw := name[storage+offset : storage+offset+length]
+		switch {
+		case (platform == 3 || platform == 0) && (platform == 0 || language == 0x409):
+			u := make([]uint16, len(raw)/2)
+			for j := range u {
+				u[j] = binary.BigEndian.Uint16(raw[2*j:])
+			}
+			names[id] = string(utf16.Decode(u))
+		case platform == 1 && language == 0:
+			if _, ok := names[id]; !ok {
+				names[id] = string(raw)
+			}
+		}
+	}
+	return names
+}
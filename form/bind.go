@@ -0,0 +1,169 @@
+// Package form binds submitted forms into structs and validates them, reporting
+// one message per invalid field for the formfield partial to render inline.
+//
+// Fields are matched by their `form` tag, or their lowercased name, and checked
+// against the comma separated rules of their `validate` tag. Values are bound
+// as submitted; the trim option strips surrounding whitespace from a field's
+// text, which suits names and addresses but never passwords:
+//
+//	type Signup struct {
+//		Email    string `form:"email,trim" validate:"required,email"`
+//		Password string `form:"password" validate:"required,min=12"`
+//		Age      int    `form:"age" validate:"min=18"`
+//	}
+package form
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Bind parses the request form and copies its values into the struct dst
+// points to. Values that cannot be converted to their field's type are reported
+// as Errors, keyed by field name; the remaining fields are still bound.
+func Bind(r *http.Request, dst any) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	return BindValues(r.Form, dst)
+}
+
+// BindValues is Bind for already parsed values.
+func BindValues(values url.Values, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return errors.New("form: Bind needs a pointer to a struct")
+	}
+	v = v.Elem()
+
+	errs := make(Errors)
+	for _, f := range fields(v.Type()) {
+		raw, ok := values[f.name]
+		if !ok {
+			// unchecked checkboxes are not submitted at all
+			if fv := v.FieldByIndex(f.index); fv.Kind() == reflect.Bool {
+				fv.SetBool(false)
+			}
+			continue
+		}
+		if err := set(v.FieldByIndex(f.index), raw, f.trim); err != nil {
+			errs.Add(f.name, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// field is a bindable struct field.
+type field struct {
+	name  string
+	index []int
+	rules string
+	trim  bool // strip surrounding whitespace from text
+}
+
+// fields returns the exported fields of t with their form names and options,
+// skipping those tagged `form:"-"`.
+func fields(t reflect.Type) []field {
+	var out []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		trim := slices.Contains(strings.Split(opts, ","), "trim")
+		out = append(out, field{name: name, index: sf.Index, rules: sf.Tag.Get("validate"), trim: trim})
+	}
+	return out
+}
+
+// set converts raw to the type of v, trimming text when trim is set. Slices take
+// every value, other kinds the first.
+func set(v reflect.Value, raw []string, trim bool) error {
+	if v.Kind() == reflect.Slice {
+		s := reflect.MakeSlice(v.Type(), len(raw), len(raw))
+		for i, r := range raw {
+			if err := setScalar(s.Index(i), r, trim); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return setScalar(v, raw[0], trim)
+}
+
+// setScalar converts raw to the type of v. Numbers and booleans ignore
+// surrounding whitespace, text keeps it unless trim is set.
+func setScalar(v reflect.Value, raw string, trim bool) error {
+	if v.Kind() == reflect.String {
+		if trim {
+			raw = strings.TrimSpace(raw)
+		}
+		v.SetString(raw)
+		return nil
+	}
+	raw = strings.TrimSpace(raw)
+	switch v.Kind() {
+	case reflect.Bool:
+		// browsers send "on" for checkboxes without a value
+		b := raw == "on"
+		if !b && raw != "" {
+			var err error
+			if b, err = strconv.ParseBool(raw); err != nil {
+				return errors.New("Must be true or false.")
+			}
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("Must be a whole number.")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if raw == "" {
+			v.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("Must be a positive whole number.")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if raw == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return errors.New("Must be a number.")
+		}
+		v.SetFloat(n)
+	default:
+		return errors.New("unsupported field type " + v.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package form
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/a-h/templ"
+	"github.com/nosvagor/hgmx/htmx"
+)
+
+// FieldHandler returns a handler validating a single field on blur. It binds
+// the posted form into a new T, checks only the field named by the
+// HX-Trigger-Name header, or the "field" query parameter, and responds with
+// render(d, name, msg), typically the re-rendered formfield.
+//
+//	mux.Handle("POST /signup/validate", form.FieldHandler(form.Default, func(d *forms.SignupData, name, msg string) templ.Component {
+//		return partials.Pick(d.Fields("/signup/validate", form.Errors{name: msg}), name)
+//	}))
+func FieldHandler[T any](v *Validator, render func(d *T, name, msg string) templ.Component) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get(htmx.HeaderTriggerName)
+		if name == "" {
+			name = r.URL.Query().Get("field")
+		}
+
+		d := new(T)
+		if !hasField(reflect.TypeOf(d).Elem(), name) {
+			http.Error(w, "unknown form field", http.StatusBadRequest)
+			return
+		}
+
+		msg := ""
+		if err := Bind(r, d); err != nil {
+			var errs Errors
+			if !errors.As(err, &errs) {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			msg = errs.Get(name)
+		}
+		if msg == "" {
+			msg = v.ValidateField(d, name)
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := render(d, name, msg).Render(r.Context(), w); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	})
+}
+
+// hasField reports whether t, a struct type, binds a form field called name.
+func hasField(t reflect.Type, name string) bool {
+	if t.Kind() != reflect.Struct || name == "" {
+		return false
+	}
+	for _, f := range fields(t) {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package form
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/nosvagor/hgmx/htmx"
+)
+
+type signup struct {
+	Name     string   `form:"name,trim" validate:"required,max=5"`
+	Email    string   `form:",trim" validate:"email"`
+	Password string   `form:"password"`
+	Age      int      `form:"age" validate:"min=18,max=130"`
+	Code     string   `form:"code" validate:"regex=^[a-z]{2,3}$"`
+	Tags     []string `form:"tag" validate:"max=2"`
+	Terms    bool     `form:"terms" validate:"required"`
+	Internal string   `form:"-"`
+}
+
+func post(values url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestBind(t *testing.T) {
+	var s signup
+	err := Bind(post(url.Values{
+		"name": {" ada "}, "email": {" ada@example.com\n"}, "password": {" secret "}, "age": {" 36"},
+		"tag": {"a", "b"}, "terms": {"on"}, "Internal": {"x"},
+	}), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "ada" || s.Email != "ada@example.com" || s.Age != 36 || !s.Terms || s.Internal != "" {
+		t.Errorf("Bind() = %+v", s)
+	}
+	if s.Password != " secret " {
+		t.Errorf("Password = %q, want it untrimmed", s.Password)
+	}
+	if strings.Join(s.Tags, ",") != "a,b" {
+		t.Errorf("Tags = %q", s.Tags)
+	}
+
+	err = Bind(post(url.Values{"age": {"old"}}), &s)
+	var errs Errors
+	if !errors.As(err, &errs) || errs.Get("age") == "" {
+		t.Errorf("Bind(age=old) = %v, want an age error", err)
+	}
+	if s.Terms {
+		t.Error("unchecked checkbox left Terms true")
+	}
+
+	if err := Bind(post(nil), s); err == nil {
+		t.Error("Bind(non-pointer) succeeded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := signup{Name: "ada", Email: "ada@example.com", Age: 36, Code: "ab", Terms: true}
+	if errs := Validate(&valid); len(errs) != 0 {
+		t.Fatalf("Validate(valid) = %v", errs)
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(*signup)
+		field string
+		want  string
+	}{
+		{"required", func(s *signup) { s.Name = "" }, "name", "Required."},
+		{"required bool", func(s *signup) { s.Terms = false }, "terms", "Required."},
+		{"max length", func(s *signup) { s.Name = "adalovelace" }, "name", "Must be at most 5 characters."},
+		{"max runes", func(s *signup) { s.Name = "ÅÅÅÅÅ" }, "name", ""},
+		{"email", func(s *signup) { s.Email = "ada@" }, "email", "Enter a valid email address."},
+		{"email display name", func(s *signup) { s.Email = "Ada <ada@example.com>" }, "email", "Enter a valid email address."},
+		{"optional empty", func(s *signup) { s.Email = "" }, "email", ""},
+		{"min value", func(s *signup) { s.Age = 12 }, "age", "Must be at least 18."},
+		{"max items", func(s *signup) { s.Tags = []string{"a", "b", "c"} }, "tag", "Must be at most 2 items."},
+		{"regex", func(s *signup) { s.Code = "abcd" }, "code", "Invalid format."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			tt.edit(&s)
+			errs := Validate(&s)
+			if got := errs.Get(tt.field); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.field, got, tt.want)
+			}
+			if got := ValidateField(&s, tt.field); got != tt.want {
+				t.Errorf("ValidateField(%s) = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	v := New()
+	v.Register("even", func(value any, _ string) error {
+		if value.(int)%2 != 0 {
+			return errors.New("Must be even.")
+		}
+		return nil
+	})
+
+	type count struct {
+		N int `form:"n" validate:"required,even"`
+	}
+	if got := v.Validate(&count{N: 3}).Get("n"); got != "Must be even." {
+		t.Errorf("n = %q", got)
+	}
+	if errs := v.Validate(&count{N: 4}); len(errs) != 0 {
+		t.Errorf("Validate(4) = %v", errs)
+	}
+}
+
+func TestRegisterFromRule(t *testing.T) {
+	v := New()
+	v.Register("lazy", func(any, string) error {
+		v.Register("never", func(any, string) error { return errors.New("Never.") })
+		return nil
+	})
+
+	type lazy struct {
+		S string `form:"s" validate:"lazy"`
+	}
+	done := make(chan Errors)
+	go func() { done <- v.Validate(&lazy{S: "x"}) }()
+	select {
+	case errs := <-done:
+		if len(errs) != 0 {
+			t.Errorf("Validate() = %v", errs)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a rule calling Register deadlocked")
+	}
+
+	type never struct {
+		S string `form:"s" validate:"never"`
+	}
+	if got := v.Validate(&never{S: "x"}).Get("s"); got != "Never." {
+		t.Errorf("s = %q, want the rule registered by the first one", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	var s signup
+	errs, err := Parse(post(url.Values{"name": {"ada"}, "age": {"x"}, "email": {"nope"}}), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Errors{"age": "Must be a whole number.", "email": "Enter a valid email address.", "terms": "Required."}
+	if len(errs) != len(want) {
+		t.Fatalf("Parse() = %v, want %v", errs, want)
+	}
+	for field, msg := range want {
+		if errs[field] != msg {
+			t.Errorf("%s = %q, want %q", field, errs[field], msg)
+		}
+	}
+	if s.Name != "ada" {
+		t.Errorf("valid fields were not bound: %+v", s)
+	}
+}
+
+func TestFieldHandler(t *testing.T) {
+	h := FieldHandler(Default, func(d *signup, name, msg string) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "%s=%q:%s", name, d.Email, msg)
+			return err
+		})
+	})
+
+	tests := []struct {
+		name    string
+		trigger string
+		values  url.Values
+		status  int
+		want    string
+	}{
+		{"invalid", "email", url.Values{"email": {"ada@"}}, http.StatusOK, `email="ada@":Enter a valid email address.`},
+		{"valid", "email", url.Values{"email": {"ada@example.com"}, "name": {""}}, http.StatusOK, `email="ada@example.com":`},
+		{"bind error", "age", url.Values{"age": {"x"}}, http.StatusOK, `age="":Must be a whole number.`},
+		{"unknown", "Internal", nil, http.StatusBadRequest, ""},
+		{"missing", "", nil, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := post(tt.values)
+			r.Header.Set(htmx.HeaderTriggerName, tt.trigger)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status == http.StatusOK && rec.Body.String() != tt.want {
+				t.Errorf("body = %s, want %s", rec.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Errors maps form field names to the message shown next to the field.
+type Errors map[string]string
+
+// Add records msg for field unless it already has a message.
+func (e Errors) Add(field, msg string) {
+	if _, ok := e[field]; !ok {
+		e[field] = msg
+	}
+}
+
+// Get returns the message for field, or "" if it is valid.
+func (e Errors) Get(field string) string {
+	return e[field]
+}
+
+// Error lists every message, sorted by field.
+func (e Errors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for i, name := range names {
+		names[i] = name + ": " + e[name]
+	}
+	return "form: " + strings.Join(names, "; ")
+}
+
+// Rule checks a field value against the parameter written after '=' in the
+// validate tag, e.g. "3" for min=3. The returned error's text is the message
+// shown to the user.
+type Rule func(value any, param string) error
+
+// Validator checks structs against the rules named in their validate tags.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+// Default is the Validator used by the package level functions.
+var Default = New()
+
+// New returns a Validator with the built in rules:
+//
+//	required   not empty, or true for a bool
+//	email      a bare address like name@example.com
+//	min=N      at least N characters, items, or a value of at least N
+//	max=N      at most N characters, items, or a value of at most N
+//	regex=RE   matches RE; must be the last rule as RE may contain commas
+func New() *Validator {
+	return &Validator{rules: map[string]Rule{
+		"required": required,
+		"email":    email,
+		"min":      minRule,
+		"max":      maxRule,
+		"regex":    regex,
+	}}
+}
+
+// Register adds a custom rule usable as name in validate tags, replacing any
+// rule already called name.
+//
+//	form.Register("username", func(v any, _ string) error {
+//		if taken(v.(string)) {
+//			return errors.New("That username is taken.")
+//		}
+//		return nil
+//	})
+func (v *Validator) Register(name string, rule Rule) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = rule
+}
+
+// Validate checks every field of the struct dst points to and returns the
+// first failing rule of each invalid field. Empty fields without the required
+// rule are not checked further.
+func (v *Validator) Validate(dst any) Errors {
+	errs := make(Errors)
+	s := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range fields(s.Type()) {
+		if msg := v.check(s.FieldByIndex(f.index), f.rules); msg != "" {
+			errs[f.name] = msg
+		}
+	}
+	return errs
+}
+
+// ValidateField checks only the field called name, returning its message or
+// "" if it is valid or unknown.
+func (v *Validator) ValidateField(dst any, name string) string {
+	s := reflect.Indirect(reflect.ValueOf(dst))
+	for _, f := range fields(s.Type()) {
+		if f.name == name {
+			return v.check(s.FieldByIndex(f.index), f.rules)
+		}
+	}
+	return ""
+}
+
+// Parse binds the request form into dst and validates it. Conversion and rule
+// failures are merged into errs, err is only set when the form cannot be read.
+//
+//	var d forms.LoginData
+//	errs, err := form.Parse(r, &d)
+//	if err != nil { ... }
+//	if len(errs) > 0 {
+//		return views.Render(w, r, forms.Login(LoginURL, "", d, errs))
+//	}
+func (v *Validator) Parse(r *http.Request, dst any) (errs Errors, err error) {
+	errs = make(Errors)
+	if err := Bind(r, dst); err != nil {
+		var bindErrs Errors
+		if !errors.As(err, &bindErrs) {
+			return nil, err
+		}
+		for name, msg := range bindErrs {
+			errs.Add(name, msg)
+		}
+	}
+	for name, msg := range v.Validate(dst) {
+		errs.Add(name, msg)
+	}
+	return errs, nil
+}
+
+// check runs rules against fv and returns the first failure's message.
+func (v *Validator) check(fv reflect.Value, rules string) string {
+	if rules == "" {
+		return ""
+	}
+
+	var names, params []string
+	for rules != "" {
+		var rule string
+		if strings.HasPrefix(rules, "regex=") {
+			rule, rules = rules, ""
+		} else {
+			rule, rules, _ = strings.Cut(rules, ",")
+		}
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		names = append(names, name)
+		params = append(params, param)
+	}
+
+	if fv.IsZero() || (fv.Kind() == reflect.Slice && fv.Len() == 0) {
+		if slices.Contains(names, "required") {
+			return "Required."
+		}
+		return ""
+	}
+
+	// rules run unlocked, so a rule may Register others
+	v.mu.RLock()
+	checks := make([]Rule, len(names))
+	for i, name := range names {
+		checks[i] = v.rules[name]
+	}
+	v.mu.RUnlock()
+
+	for i, rule := range checks {
+		if rule == nil {
+			return fmt.Sprintf("form: unknown rule %q", names[i])
+		}
+		if err := rule(fv.Interface(), params[i]); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+func required(value any, _ string) error {
+	if reflect.ValueOf(value).IsZero() {
+		return errors.New("Required.")
+	}
+	return nil
+}
+
+func email(value any, _ string) error {
+	s, _ := value.(string)
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || !strings.Contains(s[strings.LastIndex(s, "@")+1:], ".") {
+		return errors.New("Enter a valid email address.")
+	}
+	return nil
+}
+
+func minRule(value any, param string) error {
+	n, size, unit, err := measure(value, param)
+	if err != nil {
+		return err
+	}
+	if size < n {
+		return fmt.Errorf("Must be at least %s%s.", param, unit)
+	}
+	return nil
+}
+
+func maxRule(value any, param string) error {
+	n, size, unit, err := measure(value, param)
+	if err != nil {
+		return err
+	}
+	if size > n {
+		return fmt.Errorf("Must be at most %s%s.", param, unit)
+	}
+	return nil
+}
+
+// measure returns the limit param and the size of value compared against it:
+// the length of strings and slices, or the value of numbers.
+func measure(value any, param string) (limit, size float64, unit string, err error) {
+	limit, err = strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("form: invalid limit %q", param)
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return limit, float64(utf8.RuneCountInString(v.String())), " characters", nil
+	case reflect.Slice:
+		return limit, float64(v.Len()), " items", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return limit, float64(v.Int()), "", nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return limit, float64(v.Uint()), "", nil
+	case reflect.Float32, reflect.Float64:
+		return limit, v.Float(), "", nil
+	}
+	return 0, 0, "", fmt.Errorf("form: cannot measure %s", v.Type())
+}
+
+var patterns sync.Map // regex source → *regexp.Regexp
+
+func regex(value any, param string) error {
+	re, ok := patterns.Load(param)
+	if !ok {
+		compiled, err := regexp.Compile(param)
+		if err != nil {
+			return fmt.Errorf("form: invalid regex %q", param)
+		}
+		re, _ = patterns.LoadOrStore(param, compiled)
+	}
+	if !re.(*regexp.Regexp).MatchString(fmt.Sprint(value)) {
+		return errors.New("Invalid format.")
+	}
+	return nil
+}
+
+// Register adds a custom rule to the Default validator.
+func Register(name string, rule Rule) {
+	Default.Register(name, rule)
+}
+
+// Validate checks dst with the Default validator.
+func Validate(dst any) Errors {
+	return Default.Validate(dst)
+}
+
+// ValidateField checks the field called name with the Default validator.
+func ValidateField(dst any, name string) string {
+	return Default.ValidateField(dst, name)
+}
+
+// Parse binds and validates with the Default validator.
+func Parse(r *http.Request, dst any) (Errors, error) {
+	return Default.Parse(r, dst)
+}
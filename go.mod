@@ -5,8 +5,12 @@ go 1.24
 require (
 	github.com/a-h/templ v0.3.865
 	github.com/alltom/oklab v1.0.0
+	github.com/andybalholm/brotli v1.1.1
 	github.com/fatih/color v1.16.0
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/gorilla/websocket v1.5.3
 	github.com/spf13/cobra v1.9.1
+	golang.org/x/net v0.39.0
 )
 
 require (
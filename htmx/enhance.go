@@ -0,0 +1,123 @@
+package htmx
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// Enhance returns middleware that lets htmx endpoints serve plain form posts,
+// so pages keep working without JavaScript. For unsafe requests not made by
+// htmx it turns HX-Redirect and HX-Location into a 303 See Other, HX-Refresh
+// into a 303 back to the referring page, and wraps HTML fragments in layout.
+// htmx requests and safe methods, already rendered in full by Render, pass
+// through untouched.
+//
+//	http.ListenAndServe(":8080", htmx.Enhance(views.Full)(mux))
+func Enhance(layout Layout) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsRequest(r) || r.Method == http.MethodGet || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			fw := &fallbackWriter{ResponseWriter: w}
+			next.ServeHTTP(fw, r)
+			fw.finish(r, layout)
+		})
+	}
+}
+
+// fallbackWriter buffers a response so its htmx headers can be translated
+// before anything reaches the client.
+type fallbackWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (fw *fallbackWriter) WriteHeader(status int) {
+	if fw.status == 0 {
+		fw.status = status
+	}
+}
+
+func (fw *fallbackWriter) Write(b []byte) (int, error) {
+	fw.WriteHeader(http.StatusOK)
+	return fw.body.Write(b)
+}
+
+// finish writes the buffered response, translated for a browser without htmx.
+func (fw *fallbackWriter) finish(r *http.Request, layout Layout) {
+	w, h := fw.ResponseWriter, fw.Header()
+	status := fw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if target := redirectTarget(r, h); target != "" {
+		for name := range h {
+			if strings.HasPrefix(name, "Hx-") {
+				h.Del(name)
+			}
+		}
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		h.Set("Location", target)
+		w.WriteHeader(http.StatusSeeOther)
+		return
+	}
+
+	// responses without a content type are assumed to be HTML, as sniffing
+	// does not recognise most fragments
+	body := fw.body.Bytes()
+	contentType := h.Get("Content-Type")
+	if status < 300 && len(body) > 0 && (contentType == "" || strings.HasPrefix(contentType, "text/html")) && !isDocument(body) {
+		h.Del("Content-Length")
+		h.Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		layout(templ.Raw(string(body))).Render(r.Context(), w)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// redirectTarget returns where an htmx redirect header asks the browser to go,
+// or "" if there is none.
+func redirectTarget(r *http.Request, h http.Header) string {
+	if url := h.Get(HeaderRedirect); url != "" {
+		return url
+	}
+	if location := h.Get(HeaderLocation); location != "" {
+		if !strings.HasPrefix(location, "{") {
+			return location
+		}
+		var l Location
+		if json.Unmarshal([]byte(location), &l) == nil {
+			return l.Path
+		}
+	}
+	if h.Get(HeaderRefresh) == "true" {
+		if referer := r.Referer(); referer != "" {
+			return referer
+		}
+		return r.URL.RequestURI()
+	}
+	return ""
+}
+
+// isDocument reports whether body is a full HTML document rather than a
+// fragment.
+func isDocument(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	if len(body) > 9 {
+		body = body[:9]
+	}
+	prefix := strings.ToLower(string(body))
+	return strings.HasPrefix(prefix, "<!doctype") || strings.HasPrefix(prefix, "<html")
+}
@@ -0,0 +1,122 @@
+package htmx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnhance(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		htmx     bool
+		handler  http.HandlerFunc
+		status   int
+		location string
+		body     string
+	}{
+		{
+			name:   "redirect",
+			method: http.MethodPost,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				NewResponse().Redirect("/done").Apply(w)
+				w.Write([]byte("<p>ignored</p>"))
+			},
+			status:   http.StatusSeeOther,
+			location: "/done",
+		},
+		{
+			name:   "location object",
+			method: http.MethodPost,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				NewResponse().Location(Location{Path: "/todos", Target: "#list"}).Apply(w)
+			},
+			status:   http.StatusSeeOther,
+			location: "/todos",
+		},
+		{
+			name:   "refresh",
+			method: http.MethodDelete,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				NewResponse().Refresh().Apply(w)
+			},
+			status:   http.StatusSeeOther,
+			location: "/from",
+		},
+		{
+			name:   "fragment",
+			method: http.MethodPost,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("<form>errors</form>"))
+			},
+			status: http.StatusOK,
+			body:   "<html><body><form>errors</form></body></html>",
+		},
+		{
+			name:   "document",
+			method: http.MethodPost,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("<!DOCTYPE html><html></html>"))
+			},
+			status: http.StatusOK,
+			body:   "<!DOCTYPE html><html></html>",
+		},
+		{
+			name:   "error",
+			method: http.MethodPost,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "nope", http.StatusForbidden)
+			},
+			status: http.StatusForbidden,
+			body:   "nope\n",
+		},
+		{
+			name:   "htmx untouched",
+			method: http.MethodPost,
+			htmx:   true,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				NewResponse().Redirect("/done").Apply(w)
+				w.Write([]byte("<p>ok</p>"))
+			},
+			status: http.StatusOK,
+			body:   "<p>ok</p>",
+		},
+		{
+			name:   "get untouched",
+			method: http.MethodGet,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("<p>ok</p>"))
+			},
+			status: http.StatusOK,
+			body:   "<p>ok</p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/todos", nil)
+			r.Header.Set("Referer", "/from")
+			if tt.htmx {
+				r.Header.Set(HeaderRequest, "true")
+			}
+			rec := httptest.NewRecorder()
+			Enhance(layout)(tt.handler).ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+			if tt.status == http.StatusSeeOther {
+				if got := rec.Header().Get(HeaderRedirect) + rec.Header().Get(HeaderLocation); got != "" {
+					t.Errorf("htmx headers left on redirect: %q", got)
+				}
+				return
+			}
+			if got := rec.Body.String(); got != tt.body {
+				t.Errorf("body = %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package htmx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/todos", nil)
+	r.Header.Set(HeaderRequest, "true")
+	r.Header.Set(HeaderBoosted, "true")
+	r.Header.Set(HeaderCurrentURL, "http://localhost:8080/todos?page=2")
+	r.Header.Set(HeaderPrompt, "a+b")
+	r.Header.Set(HeaderTarget, "list")
+	r.Header.Set(HeaderTriggerName, "title")
+	r.Header.Set(HeaderTrigger, "new-todo")
+
+	got := NewRequest(r)
+	want := Request{
+		Request:     true,
+		Boosted:     true,
+		CurrentURL:  "http://localhost:8080/todos?page=2",
+		Prompt:      "a+b",
+		Target:      "list",
+		TriggerName: "title",
+		Trigger:     "new-todo",
+	}
+	if got != want {
+		t.Errorf("NewRequest() = %+v, want %+v", got, want)
+	}
+	if u := got.URL(); u == nil || u.Query().Get("page") != "2" {
+		t.Errorf("URL() = %v, want page=2", u)
+	}
+}
+
+func TestNewRequestAutoEncoded(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderPrompt, "gr%C3%BC%C3%9Fe")
+	r.Header.Set(HeaderPrompt+"-URI-AutoEncoded", "true")
+
+	if got := NewRequest(r).Prompt; got != "grüße" {
+		t.Errorf("Prompt = %q, want %q", got, "grüße")
+	}
+}
+
+func TestRequestKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		request bool
+		boosted bool
+		history bool
+		partial bool
+	}{
+		{"browser", nil, false, false, false, false},
+		{"htmx", map[string]string{HeaderRequest: "true"}, true, false, false, true},
+		{"boosted", map[string]string{HeaderRequest: "true", HeaderBoosted: "true"}, true, true, false, false},
+		{"history", map[string]string{HeaderRequest: "true", HeaderHistoryRestoreRequest: "true"}, true, false, true, false},
+		{"not true", map[string]string{HeaderRequest: "false"}, false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := IsRequest(r); got != tt.request {
+				t.Errorf("IsRequest() = %v, want %v", got, tt.request)
+			}
+			if got := IsBoosted(r); got != tt.boosted {
+				t.Errorf("IsBoosted() = %v, want %v", got, tt.boosted)
+			}
+			if got := IsHistoryRestoreRequest(r); got != tt.history {
+				t.Errorf("IsHistoryRestoreRequest() = %v, want %v", got, tt.history)
+			}
+			if got := IsPartial(r); got != tt.partial {
+				t.Errorf("IsPartial() = %v, want %v", got, tt.partial)
+			}
+		})
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func(*Response) *Response
+		header string
+		want   string
+	}{
+		{"redirect", func(r *Response) *Response { return r.Redirect("/login") }, HeaderRedirect, "/login"},
+		{"refresh", func(r *Response) *Response { return r.Refresh() }, HeaderRefresh, "true"},
+		{"push url", func(r *Response) *Response { return r.PushURL("/todos/1") }, HeaderPushURL, "/todos/1"},
+		{"prevent push url", func(r *Response) *Response { return r.PreventPushURL() }, HeaderPushURL, "false"},
+		{"replace url", func(r *Response) *Response { return r.ReplaceURL("/todos") }, HeaderReplaceURL, "/todos"},
+		{"prevent replace url", func(r *Response) *Response { return r.PreventReplaceURL() }, HeaderReplaceURL, "false"},
+		{"reswap", func(r *Response) *Response { return r.Reswap(SwapOuterHTML.With("transition:true")) }, HeaderReswap, "outerHTML transition:true"},
+		{"retarget", func(r *Response) *Response { return r.Retarget("#errors") }, HeaderRetarget, "#errors"},
+		{"reselect", func(r *Response) *Response { return r.Reselect("#content") }, HeaderReselect, "#content"},
+		{"location path", func(r *Response) *Response { return r.Location(Location{Path: "/todos"}) }, HeaderLocation, "/todos"},
+		{"location json", func(r *Response) *Response {
+			return r.Location(Location{Path: "/todos", Target: "#main", Swap: SwapInnerHTML})
+		}, HeaderLocation, `{"path":"/todos","target":"#main","swap":"innerHTML"}`},
+		{"trigger names", func(r *Response) *Response { return r.Trigger("saved", nil).Trigger("closeModal", nil) }, HeaderTrigger, "saved, closeModal"},
+		{"trigger json", func(r *Response) *Response {
+			return r.Trigger("showMessage", map[string]string{"level": "info"}).Trigger("saved", nil)
+		}, HeaderTrigger, `{"showMessage":{"level":"info"},"saved":null}`},
+		{"trigger after settle", func(r *Response) *Response { return r.TriggerAfterSettle("settled", nil) }, HeaderTriggerAfterSettle, "settled"},
+		{"trigger after swap", func(r *Response) *Response { return r.TriggerAfterSwap("swapped", 42) }, HeaderTriggerAfterSwap, `{"swapped":42}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			res := tt.build(NewResponse())
+			if err := res.Apply(rec); err != nil {
+				t.Fatalf("Apply() error: %v", err)
+			}
+			if got := rec.Header().Get(tt.header); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.header, got, tt.want)
+			}
+			if got, _ := res.Get(tt.header); got != tt.want {
+				t.Errorf("Get(%s) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseTriggerPayload(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := NewResponse().Trigger("todoAdded", struct {
+			ID    int    `json:"id"`
+			Title string `json:"title"`
+		}{7, `say "hi"`})
+		if err := res.Apply(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/todos", nil))
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	var events map[string]struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal([]byte(rec.Header().Get(HeaderTrigger)), &events); err != nil {
+		t.Fatalf("HX-Trigger is not JSON: %v", err)
+	}
+	if e := events["todoAdded"]; e.ID != 7 || e.Title != `say "hi"` {
+		t.Errorf("todoAdded = %+v", e)
+	}
+}
+
+func TestResponseApplyError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := NewResponse().Trigger("bad", make(chan int)).Apply(rec); err == nil {
+		t.Error("Apply() expected error for unencodable detail")
+	}
+	if err := NewResponse().Location(Location{Path: "/", Values: map[string]any{"bad": func() {}}}).Apply(rec); err == nil {
+		t.Error("Apply() expected error for unencodable location")
+	}
+}
@@ -0,0 +1,79 @@
+package htmx
+
+import (
+	"context"
+	"io"
+
+	"github.com/a-h/templ"
+)
+
+// OOB is a component swapped out of band into the element with id Target,
+// alongside the primary content of a response. See https://htmx.org/attributes/hx-swap-oob/
+type OOB struct {
+	Target    string // id of the element to swap into, without '#'
+	Swap      Swap   // defaults to SwapOuterHTML
+	Component templ.Component
+}
+
+// Render writes the component inside a wrapper carrying hx-swap-oob. For
+// outerHTML swaps the wrapper takes the target id and replaces the target, for
+// every other strategy its children are swapped into the target.
+func (o OOB) Render(ctx context.Context, w io.Writer) error {
+	swap := o.Swap
+	if swap == "" {
+		swap = SwapOuterHTML
+	}
+
+	var open string
+	if swap == SwapOuterHTML {
+		open = `<div id="` + templ.EscapeString(o.Target) + `" hx-swap-oob="outerHTML">`
+	} else {
+		open = `<div hx-swap-oob="` + templ.EscapeString(string(swap)+":#"+o.Target) + `">`
+	}
+
+	if _, err := io.WriteString(w, open); err != nil {
+		return err
+	}
+	if o.Component != nil {
+		if err := o.Component.Render(ctx, w); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "</div>")
+	return err
+}
+
+// Composition is a primary component followed by out of band swaps, streamed
+// as one response.
+type Composition struct {
+	Primary templ.Component
+	OOB     []OOB
+}
+
+// Compose returns primary followed by the given out of band swaps.
+//
+//	htmx.Render(w, r, htmx.Compose(todo, htmx.OOB{Target: "count", Component: count}), views.Full)
+func Compose(primary templ.Component, oob ...OOB) *Composition {
+	return &Composition{Primary: primary, OOB: oob}
+}
+
+// With appends more out of band swaps to the composition.
+func (c *Composition) With(oob ...OOB) *Composition {
+	c.OOB = append(c.OOB, oob...)
+	return c
+}
+
+// Render writes the primary component, then every out of band swap.
+func (c *Composition) Render(ctx context.Context, w io.Writer) error {
+	if c.Primary != nil {
+		if err := c.Primary.Render(ctx, w); err != nil {
+			return err
+		}
+	}
+	for _, o := range c.OOB {
+		if err := o.Render(ctx, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package htmx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOOBRender(t *testing.T) {
+	tests := []struct {
+		name string
+		oob  OOB
+		want string
+	}{
+		{"default", OOB{Target: "count", Component: text("3")}, `<div id="count" hx-swap-oob="outerHTML">3</div>`},
+		{"beforeend", OOB{Target: "alerts", Swap: SwapBeforeEnd, Component: text("<p>hi</p>")}, `<div hx-swap-oob="beforeend:#alerts"><p>hi</p></div>`},
+		{"escaped", OOB{Target: `x"y`, Swap: SwapInnerHTML}, `<div hx-swap-oob="innerHTML:#x&#34;y"></div>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tt.oob.Render(context.Background(), &sb); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompose(t *testing.T) {
+	page := Compose(text("<li>todo</li>"), OOB{Target: "count", Component: text("1")}).
+		With(OOB{Target: "alerts", Swap: SwapBeforeEnd, Component: text("saved")})
+
+	r := httptest.NewRequest(http.MethodPost, "/todos", nil)
+	r.Header.Set(HeaderRequest, "true")
+	rec := httptest.NewRecorder()
+	if err := Render(rec, r, page, layout); err != nil {
+		t.Fatal(err)
+	}
+	want := `<li>todo</li><div id="count" hx-swap-oob="outerHTML">1</div><div hx-swap-oob="beforeend:#alerts">saved</div>`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("fragment = %q, want %q", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := Render(rec, httptest.NewRequest(http.MethodGet, "/todos", nil), page, layout); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rec.Body.String(), "<html><body><li>todo</li></body></html>"; got != want {
+		t.Errorf("document = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package htmx
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// Layout wraps a page in a full document, e.g. views.Full.
+type Layout func(content templ.Component) templ.Component
+
+// varyHeaders are the request headers that decide between a full document and
+// a fragment, so caches must key on all of them.
+var varyHeaders = []string{HeaderRequest, HeaderBoosted, HeaderHistoryRestoreRequest}
+
+// Render writes page wrapped in layout for normal navigations, boosted
+// navigations and history restoration, and page on its own for htmx swaps.
+// Out of band swaps of a Composition only apply to fragments, a full document
+// renders just its primary component.
+func Render(w http.ResponseWriter, r *http.Request, page templ.Component, layout Layout) error {
+	Vary(w, varyHeaders...)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if IsPartial(r) {
+		return page.Render(r.Context(), w)
+	}
+	if c, ok := page.(*Composition); ok {
+		page = c.Primary
+		if page == nil {
+			page = templ.NopComponent
+		}
+	}
+	return layout(page).Render(r.Context(), w)
+}
+
+// Handler returns an http.Handler that renders page with Render.
+func Handler(page templ.Component, layout Layout) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := Render(w, r, page, layout); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	})
+}
+
+// Vary adds headers to the Vary response header, skipping any already present.
+func Vary(w http.ResponseWriter, headers ...string) {
+	existing := make(map[string]bool)
+	for _, value := range w.Header().Values("Vary") {
+		for _, h := range strings.Split(value, ",") {
+			existing[http.CanonicalHeaderKey(strings.TrimSpace(h))] = true
+		}
+	}
+	for _, h := range headers {
+		if !existing[http.CanonicalHeaderKey(h)] {
+			w.Header().Add("Vary", h)
+			existing[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+}
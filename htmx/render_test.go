@@ -0,0 +1,76 @@
+package htmx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func text(s string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	})
+}
+
+func layout(content templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		io.WriteString(w, "<html><body>")
+		if err := content.Render(ctx, w); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "</body></html>")
+		return err
+	})
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"navigation", nil, "<html><body><h1>Home</h1></body></html>"},
+		{"boosted", map[string]string{HeaderRequest: "true", HeaderBoosted: "true"}, "<html><body><h1>Home</h1></body></html>"},
+		{"history restore", map[string]string{HeaderRequest: "true", HeaderHistoryRestoreRequest: "true"}, "<html><body><h1>Home</h1></body></html>"},
+		{"swap", map[string]string{HeaderRequest: "true"}, "<h1>Home</h1>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			Handler(text("<h1>Home</h1>"), layout).ServeHTTP(rec, r)
+
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			vary := strings.Join(rec.Header().Values("Vary"), ",")
+			if !strings.Contains(vary, HeaderRequest) {
+				t.Errorf("Vary = %q, want it to contain %s", vary, HeaderRequest)
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+				t.Errorf("Content-Type = %q", ct)
+			}
+		})
+	}
+}
+
+func TestVary(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Vary", "Accept-Encoding, hx-request")
+	Vary(rec, HeaderRequest, HeaderBoosted, HeaderBoosted)
+
+	got := rec.Header().Values("Vary")
+	want := []string{"Accept-Encoding, hx-request", HeaderBoosted}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Vary = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+// Package htmx implements the server side of the htmx request and response
+// header protocol. See https://htmx.org/reference/#headers
+package htmx
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Request headers sent by htmx.
+const (
+	HeaderBoosted               = "HX-Boosted"
+	HeaderCurrentURL            = "HX-Current-URL"
+	HeaderHistoryRestoreRequest = "HX-History-Restore-Request"
+	HeaderPrompt                = "HX-Prompt"
+	HeaderRequest               = "HX-Request"
+	HeaderTarget                = "HX-Target"
+	HeaderTriggerName           = "HX-Trigger-Name"
+	HeaderTrigger               = "HX-Trigger"
+)
+
+// Request holds the htmx request headers of an incoming request.
+type Request struct {
+	Request               bool   // always true for requests made by htmx
+	Boosted               bool   // the request came from an element using hx-boost
+	CurrentURL            string // the current URL of the browser
+	HistoryRestoreRequest bool   // the request is for history restoration after a cache miss
+	Prompt                string // the user response to an hx-prompt
+	Target                string // the id of the target element, if it exists
+	TriggerName           string // the name of the triggered element, if it exists
+	Trigger               string // the id of the triggered element, if it exists
+}
+
+// NewRequest reads the htmx request headers from r.
+func NewRequest(r *http.Request) Request {
+	return Request{
+		Request:               isTrue(r.Header.Get(HeaderRequest)),
+		Boosted:               isTrue(r.Header.Get(HeaderBoosted)),
+		CurrentURL:            header(r, HeaderCurrentURL),
+		HistoryRestoreRequest: isTrue(r.Header.Get(HeaderHistoryRestoreRequest)),
+		Prompt:                header(r, HeaderPrompt),
+		Target:                header(r, HeaderTarget),
+		TriggerName:           header(r, HeaderTriggerName),
+		Trigger:               header(r, HeaderTrigger),
+	}
+}
+
+// IsRequest reports whether r was made by htmx.
+func IsRequest(r *http.Request) bool {
+	return isTrue(r.Header.Get(HeaderRequest))
+}
+
+// IsBoosted reports whether r was made by an element using hx-boost.
+func IsBoosted(r *http.Request) bool {
+	return isTrue(r.Header.Get(HeaderBoosted))
+}
+
+// IsHistoryRestoreRequest reports whether r is a history restoration request,
+// which always expects a full page.
+func IsHistoryRestoreRequest(r *http.Request) bool {
+	return isTrue(r.Header.Get(HeaderHistoryRestoreRequest))
+}
+
+// IsPartial reports whether r expects a fragment to swap into the page, rather
+// than a full document: an htmx request that is neither boosted nor restoring
+// history.
+func IsPartial(r *http.Request) bool {
+	return IsRequest(r) && !IsBoosted(r) && !IsHistoryRestoreRequest(r)
+}
+
+// URL returns the parsed current URL of the browser, or nil if the header is
+// missing or malformed.
+func (r Request) URL() *url.URL {
+	if r.CurrentURL == "" {
+		return nil
+	}
+	u, err := url.Parse(r.CurrentURL)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+func isTrue(v string) bool {
+	return v == "true"
+}
+
+// header returns the value of an htmx request header. htmx URI-encodes values
+// containing non-ASCII characters and marks them with a <name>-URI-AutoEncoded
+// header.
+func header(r *http.Request, name string) string {
+	v := r.Header.Get(name)
+	if !isTrue(r.Header.Get(name + "-URI-AutoEncoded")) {
+		return v
+	}
+	if u, err := url.PathUnescape(v); err == nil {
+		return u
+	}
+	return v
+}
@@ -0,0 +1,247 @@
+package htmx
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Response headers understood by htmx.
+const (
+	HeaderLocation           = "HX-Location"
+	HeaderPushURL            = "HX-Push-Url"
+	HeaderRedirect           = "HX-Redirect"
+	HeaderRefresh            = "HX-Refresh"
+	HeaderReplaceURL         = "HX-Replace-Url"
+	HeaderReswap             = "HX-Reswap"
+	HeaderRetarget           = "HX-Retarget"
+	HeaderReselect           = "HX-Reselect"
+	HeaderTriggerAfterSettle = "HX-Trigger-After-Settle"
+	HeaderTriggerAfterSwap   = "HX-Trigger-After-Swap"
+)
+
+// StatusStopPolling tells htmx to stop polling the element that made the request.
+const StatusStopPolling = 286
+
+// Swap is an hx-swap value, optionally followed by modifiers.
+type Swap string
+
+const (
+	SwapInnerHTML   Swap = "innerHTML"
+	SwapOuterHTML   Swap = "outerHTML"
+	SwapTextContent Swap = "textContent"
+	SwapBeforeBegin Swap = "beforebegin"
+	SwapAfterBegin  Swap = "afterbegin"
+	SwapBeforeEnd   Swap = "beforeend"
+	SwapAfterEnd    Swap = "afterend"
+	SwapDelete      Swap = "delete"
+	SwapNone        Swap = "none"
+)
+
+// With appends swap modifiers, e.g. SwapOuterHTML.With("transition:true").
+func (s Swap) With(modifiers ...string) Swap {
+	return Swap(strings.Join(append([]string{string(s)}, modifiers...), " "))
+}
+
+// Location is an HX-Location value: a client side redirect that does not do a
+// full page reload. Only Path is required.
+type Location struct {
+	Path    string            `json:"path"`
+	Source  string            `json:"source,omitempty"`
+	Event   string            `json:"event,omitempty"`
+	Handler string            `json:"handler,omitempty"`
+	Target  string            `json:"target,omitempty"`
+	Swap    Swap              `json:"swap,omitempty"`
+	Values  map[string]any    `json:"values,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Select  string            `json:"select,omitempty"`
+}
+
+// Event is a client side event triggered through the HX-Trigger headers. A
+// nil Detail triggers the event without a payload.
+type Event struct {
+	Name   string
+	Detail any
+}
+
+// Response collects htmx response headers and writes them with Apply.
+//
+//	htmx.NewResponse().Retarget("#errors").Reswap(htmx.SwapOuterHTML).Apply(w)
+type Response struct {
+	headers  map[string]string
+	events   map[string][]Event
+	location *Location
+}
+
+// NewResponse returns an empty Response.
+func NewResponse() *Response {
+	return &Response{headers: make(map[string]string), events: make(map[string][]Event)}
+}
+
+// Location performs a client side redirect without a full page reload.
+func (r *Response) Location(l Location) *Response {
+	r.location = &l
+	return r
+}
+
+// Redirect performs a client side redirect with a full page reload.
+func (r *Response) Redirect(url string) *Response {
+	r.headers[HeaderRedirect] = url
+	return r
+}
+
+// Refresh performs a full refresh of the page.
+func (r *Response) Refresh() *Response {
+	r.headers[HeaderRefresh] = "true"
+	return r
+}
+
+// PushURL pushes url into the browser history.
+func (r *Response) PushURL(url string) *Response {
+	r.headers[HeaderPushURL] = url
+	return r
+}
+
+// PreventPushURL prevents the browser history from being updated.
+func (r *Response) PreventPushURL() *Response {
+	r.headers[HeaderPushURL] = "false"
+	return r
+}
+
+// ReplaceURL replaces the current URL in the location bar.
+func (r *Response) ReplaceURL(url string) *Response {
+	r.headers[HeaderReplaceURL] = url
+	return r
+}
+
+// PreventReplaceURL prevents the current URL from being replaced.
+func (r *Response) PreventReplaceURL() *Response {
+	r.headers[HeaderReplaceURL] = "false"
+	return r
+}
+
+// Reswap overrides the hx-swap of the triggering element.
+func (r *Response) Reswap(s Swap) *Response {
+	r.headers[HeaderReswap] = string(s)
+	return r
+}
+
+// Retarget overrides the hx-target of the triggering element with a CSS selector.
+func (r *Response) Retarget(selector string) *Response {
+	r.headers[HeaderRetarget] = selector
+	return r
+}
+
+// Reselect overrides the hx-select of the triggering element with a CSS selector.
+func (r *Response) Reselect(selector string) *Response {
+	r.headers[HeaderReselect] = selector
+	return r
+}
+
+// Trigger triggers a client side event as soon as the response is received.
+// detail may be nil or any value encodable as JSON.
+func (r *Response) Trigger(name string, detail any) *Response {
+	r.events[HeaderTrigger] = append(r.events[HeaderTrigger], Event{Name: name, Detail: detail})
+	return r
+}
+
+// TriggerAfterSettle triggers a client side event after the settle step.
+func (r *Response) TriggerAfterSettle(name string, detail any) *Response {
+	r.events[HeaderTriggerAfterSettle] = append(r.events[HeaderTriggerAfterSettle], Event{Name: name, Detail: detail})
+	return r
+}
+
+// TriggerAfterSwap triggers a client side event after the swap step.
+func (r *Response) TriggerAfterSwap(name string, detail any) *Response {
+	r.events[HeaderTriggerAfterSwap] = append(r.events[HeaderTriggerAfterSwap], Event{Name: name, Detail: detail})
+	return r
+}
+
+// Get returns the value that Apply will write for an htmx response header.
+func (r *Response) Get(header string) (string, error) {
+	if header == HeaderLocation && r.location != nil {
+		return encodeLocation(*r.location)
+	}
+	if events, ok := r.events[header]; ok {
+		return encodeEvents(events)
+	}
+	return r.headers[header], nil
+}
+
+// Apply writes the collected headers to w. It must be called before the
+// response status or body is written.
+func (r *Response) Apply(w http.ResponseWriter) error {
+	for header, value := range r.headers {
+		w.Header().Set(header, value)
+	}
+	if r.location != nil {
+		value, err := encodeLocation(*r.location)
+		if err != nil {
+			return err
+		}
+		w.Header().Set(HeaderLocation, value)
+	}
+	for header, events := range r.events {
+		value, err := encodeEvents(events)
+		if err != nil {
+			return err
+		}
+		w.Header().Set(header, value)
+	}
+	return nil
+}
+
+// encodeLocation encodes l as a plain path when no other field is set,
+// otherwise as JSON.
+func encodeLocation(l Location) (string, error) {
+	if l.Source == "" && l.Event == "" && l.Handler == "" && l.Target == "" &&
+		l.Swap == "" && l.Values == nil && l.Headers == nil && l.Select == "" {
+		return l.Path, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// encodeEvents encodes events as a comma separated list of names when none
+// carry a detail, otherwise as a JSON object keyed by event name, in order.
+func encodeEvents(events []Event) (string, error) {
+	plain := true
+	for _, e := range events {
+		if e.Detail != nil {
+			plain = false
+			break
+		}
+	}
+
+	if plain {
+		names := make([]string, len(events))
+		for i, e := range events {
+			names[i] = e.Name
+		}
+		return strings.Join(names, ", "), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, e := range events {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		name, err := json.Marshal(e.Name)
+		if err != nil {
+			return "", err
+		}
+		detail, err := json.Marshal(e.Detail)
+		if err != nil {
+			return "", err
+		}
+		sb.Write(name)
+		sb.WriteString(":")
+		sb.Write(detail)
+	}
+	sb.WriteString("}")
+	return sb.String(), nil
+}
@@ -0,0 +1,182 @@
+package bundle
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nosvagor/hgmx/assets"
+)
+
+func TestViews(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views.templ": {Data: []byte(`templ Full() {
+	@Style("main.min.css")
+	@Script("vendor/htmx.min.js", false)
+	<link rel="icon" href={ Asset("favicon/favicon.ico") }/>
+	<link href={ Asset("fonts/" + file) }/>
+}`)},
+		"handlers.go":        {Data: []byte(`var logo = views.Asset("img/logo.png")`)},
+		"views_templ.go":     {Data: []byte(`Asset("generated.png")`)},
+		"static/other.go":    {Data: []byte(`Asset("static.png")`)},
+		".cache/stale.templ": {Data: []byte(`Asset("hidden.png")`)},
+	}
+	refs, err := Views(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range refs {
+		got = append(got, r.String())
+	}
+	want := []string{
+		"handlers.go:1: img/logo.png",
+		"views.templ:2: css/main.min.css",
+		"views.templ:3: scripts/vendor/htmx.min.js",
+		"views.templ:4: favicon/favicon.ico",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Views() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestStatic(t *testing.T) {
+	fsys := fstest.MapFS{
+		"css/fonts.css": {Data: []byte(`src: url("../fonts/a.woff2") format("woff2");
+			background: url(data:image/png;base64,AAAA), url('/static/x.png'), url(#clip), url(img/bg.svg?v=2);`)},
+		"favicon/site.webmanifest": {Data: []byte(`{"icons":[{"src":"icon-192.png"},{"src":"https://cdn.example/x.png"}]}`)},
+	}
+	refs, err := Static(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range refs {
+		got = append(got, r.Path)
+	}
+	if want := []string{"fonts/a.woff2", "css/img/bg.svg", "favicon/icon-192.png"}; !slices.Equal(got, want) {
+		t.Errorf("Static() = %v, want %v", got, want)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for s, want := range map[string]int64{
+		"2048": 2048, "120kB": 120000, "1.5MB": 1500000, "64 KiB": 65536, "10B": 10,
+	} {
+		if got, err := ParseSize(s); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "lots", "-1kB", "kB"} {
+		if _, err := ParseSize(s); err == nil {
+			t.Errorf("ParseSize(%q) succeeded", s)
+		}
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	sizes := []Size{
+		{Path: "scripts/vendor/htmx.min.js", Raw: 50000, Gzip: 16000, Brotli: 14000},
+		{Path: "scripts/app.js", Raw: 3000, Gzip: 1200},
+		{Path: "css/main.min.css", Raw: 2000},
+	}
+	budgets, err := Budgets(map[string]string{"*.js": "15kB", "css/*.css": "5kB"}, sizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(budgets) != 2 || budgets[0].Pattern != "*.js" {
+		t.Fatalf("Budgets() = %+v", budgets)
+	}
+	if js := budgets[0]; js.Total != 15200 || js.Files != 2 || !js.Exceeded() {
+		t.Errorf("*.js budget = %+v, want 15200 bytes over budget", js)
+	}
+	if css := budgets[1]; css.Total != 2000 || css.Exceeded() {
+		t.Errorf("css/*.css budget = %+v", css)
+	}
+
+	var report strings.Builder
+	if err := WriteReport(&report, sizes, budgets); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(report.String(), "OVER BUDGET") || strings.Index(report.String(), "htmx") > strings.Index(report.String(), "main.min.css") {
+		t.Errorf("report =\n%s", report.String())
+	}
+
+	if _, err := Budgets(map[string]string{"[": "1kB"}, sizes); err == nil {
+		t.Error("Budgets() accepted a malformed pattern")
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	for _, tc := range []struct {
+		static, output string
+		ok             bool
+	}{
+		{"views/static", "dist/static", true},
+		{"views/static", "views/static-dist", true},
+		{"views/static", "views", true},
+		{"views/static", "views/static", false},
+		{"views/static", "./views/static/", false},
+		{"views/static", "views/static/dist", false},
+		{".", "dist", false},
+	} {
+		if err := CheckOutput(tc.static, tc.output); (err == nil) != tc.ok {
+			t.Errorf("CheckOutput(%q, %q) = %v", tc.static, tc.output, err)
+		}
+	}
+}
+
+func TestPruneAndSizes(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(src, "app.js"), []byte(strings.Repeat("console.log(0);\n", 100)), 0o644)
+	prev, err := assets.Fingerprint(os.DirFS(src), dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := assets.Precompress(dst); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dst, filepath.FromSlash(prev.Path("app.js")))
+	unknown := filepath.Join(dst, "app.0123456789.js")
+	os.WriteFile(unknown, []byte("not ours"), 0o644)
+
+	os.WriteFile(filepath.Join(src, "app.js"), []byte(strings.Repeat("console.log(1);\n", 100)), 0o644)
+	m, err := assets.Fingerprint(os.DirFS(src), dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Prune(dst, prev, m); err != nil || n != 3 {
+		t.Errorf("Prune() = %d, %v, want the stale file and its 2 siblings removed", n, err)
+	}
+	for _, p := range []string{stale, stale + ".gz", stale + ".br"} {
+		if _, err := os.Stat(p); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s was not removed", p)
+		}
+	}
+	if _, err := os.Stat(unknown); err != nil {
+		t.Errorf("Prune() removed a file no manifest lists: %v", err)
+	}
+	if _, err := assets.Precompress(dst); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := Prune(dst, m, m); n != 0 {
+		t.Errorf("Prune() removed %d current files", n)
+	}
+	if n, _ := Prune(dst, nil, m); n != 0 {
+		t.Errorf("Prune() without a previous manifest removed %d files", n)
+	}
+	os.Remove(unknown)
+
+	sizes, err := Sizes(os.DirFS(dst), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 1 || sizes[0].Raw != 1600 || sizes[0].Brotli == 0 || sizes[0].Transfer() != sizes[0].Brotli {
+		t.Errorf("Sizes() = %+v", sizes)
+	}
+}
@@ -0,0 +1,135 @@
+// Package bundle checks the production static bundle hgmx build writes: that
+// every asset the views and stylesheets reference exists, and that the files
+// stay within their size budgets.
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Ref is a reference to a static file, relative to the static directory.
+type Ref struct {
+	Path string
+	File string // where the reference is, relative to the scanned directory
+	Line int
+}
+
+func (r Ref) String() string {
+	if r.Line == 0 {
+		return r.File + ": " + r.Path
+	}
+	return fmt.Sprintf("%s:%d: %s", r.File, r.Line, r.Path)
+}
+
+var (
+	// assetCall matches the view helpers called with a literal path. Paths
+	// built at runtime, such as "fonts/" + file, are checked by LoadAssets.
+	assetCall = regexp.MustCompile(`\b(Asset|Style|Script)\(\s*"([^"]+)"\s*[,)]`)
+	cssURL    = regexp.MustCompile(`url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+)
+
+// callDirs are the directories the helpers resolve their path in.
+var callDirs = map[string]string{"Asset": "", "Style": "css/", "Script": "scripts/"}
+
+// Views returns the static files the .templ and hand written .go files in
+// fsys pass to Asset, Style and Script.
+func Views(fsys fs.FS) ([]Ref, error) {
+	var refs []Ref
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && strings.HasPrefix(d.Name(), ".") || d.Name() == "static" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".templ") && (!strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_templ.go")) {
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		sc := bufio.NewScanner(bytes.NewReader(b))
+		sc.Buffer(nil, len(b)+1)
+		for line := 1; sc.Scan(); line++ {
+			for _, m := range assetCall.FindAllStringSubmatch(sc.Text(), -1) {
+				refs = append(refs, Ref{Path: callDirs[m[1]] + m[2], File: p, Line: line})
+			}
+		}
+		return sc.Err()
+	})
+	return refs, err
+}
+
+// Static returns the files the stylesheets and web app manifests in fsys
+// refer to with relative URLs.
+func Static(fsys fs.FS) ([]Ref, error) {
+	var refs []Ref
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		var urls []string
+		switch path.Ext(p) {
+		case ".css":
+			b, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return err
+			}
+			for _, m := range cssURL.FindAllSubmatch(b, -1) {
+				urls = append(urls, string(m[1]))
+			}
+		case ".webmanifest":
+			b, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return err
+			}
+			var manifest struct {
+				Icons []struct {
+					Src string `json:"src"`
+				} `json:"icons"`
+			}
+			if err := json.Unmarshal(b, &manifest); err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			for _, icon := range manifest.Icons {
+				urls = append(urls, icon.Src)
+			}
+		default:
+			return nil
+		}
+		for _, u := range urls {
+			if target, ok := resolve(path.Dir(p), u); ok {
+				refs = append(refs, Ref{Path: target, File: p})
+			}
+		}
+		return nil
+	})
+	return refs, err
+}
+
+// resolve returns the file a relative URL in dir points to. Absolute URLs,
+// data URIs and fragments are not files of the bundle.
+func resolve(dir, u string) (string, bool) {
+	if u == "" || strings.HasPrefix(u, "/") || strings.HasPrefix(u, "#") || strings.Contains(u, ":") {
+		return "", false
+	}
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	target := path.Join(dir, u)
+	if strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	return target, true
+}
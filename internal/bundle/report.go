@@ -0,0 +1,239 @@
+package bundle
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nosvagor/hgmx/assets"
+)
+
+// Size is the size of a bundled file, and of its precompressed siblings when
+// there are any.
+type Size struct {
+	Path   string // original path
+	Hashed string
+	Raw    int64
+	Gzip   int64
+	Brotli int64
+}
+
+// Transfer is what a browser supporting brotli downloads.
+func (s Size) Transfer() int64 {
+	switch {
+	case s.Brotli > 0:
+		return s.Brotli
+	case s.Gzip > 0:
+		return s.Gzip
+	}
+	return s.Raw
+}
+
+// Sizes measures every file of m in the bundle fsys.
+func Sizes(fsys fs.FS, m *assets.Manifest) ([]Size, error) {
+	var sizes []Size
+	for _, p := range m.Paths() {
+		hashed := m.Path(p)
+		info, err := fs.Stat(fsys, hashed)
+		if err != nil {
+			return nil, err
+		}
+		s := Size{Path: p, Hashed: hashed, Raw: info.Size()}
+		if info, err := fs.Stat(fsys, hashed+".gz"); err == nil {
+			s.Gzip = info.Size()
+		}
+		if info, err := fs.Stat(fsys, hashed+".br"); err == nil {
+			s.Brotli = info.Size()
+		}
+		sizes = append(sizes, s)
+	}
+	return sizes, nil
+}
+
+// Budget limits the total transfer size of the files matching Pattern. A
+// pattern with a slash is matched against the whole path, one without against
+// the file name, so "*.js" covers every script.
+type Budget struct {
+	Pattern string
+	Max     int64
+	Total   int64
+	Files   int
+}
+
+// Exceeded reports whether the files are over budget.
+func (b Budget) Exceeded() bool {
+	return b.Total > b.Max
+}
+
+// Match reports whether the file at p counts against the budget.
+func (b Budget) Match(p string) bool {
+	if !strings.Contains(b.Pattern, "/") {
+		p = path.Base(p)
+	}
+	ok, _ := path.Match(b.Pattern, p)
+	return ok
+}
+
+// Budgets parses the configured budgets, pattern to size such as "100kB", and
+// totals sizes against them, in pattern order.
+func Budgets(config map[string]string, sizes []Size) ([]Budget, error) {
+	var budgets []Budget
+	for pattern, limit := range config {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("budget %q: %w", pattern, err)
+		}
+		max, err := ParseSize(limit)
+		if err != nil {
+			return nil, fmt.Errorf("budget %q: %w", pattern, err)
+		}
+		b := Budget{Pattern: pattern, Max: max}
+		for _, s := range sizes {
+			if b.Match(s.Path) {
+				b.Total += s.Transfer()
+				b.Files++
+			}
+		}
+		budgets = append(budgets, b)
+	}
+	slices.SortFunc(budgets, func(a, b Budget) int { return strings.Compare(a.Pattern, b.Pattern) })
+	return budgets, nil
+}
+
+// units are the size suffixes ParseSize accepts, longest first.
+var units = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20},
+	{"kB", 1e3}, {"KB", 1e3}, {"MB", 1e6},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as "120kB", "1.5MB", "64KiB" or "2048".
+func ParseSize(s string) (int64, error) {
+	n, mult := strings.TrimSpace(s), 1.0
+	for _, u := range units {
+		if v, ok := strings.CutSuffix(n, u.suffix); ok {
+			n, mult = strings.TrimSpace(v), u.bytes
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(n, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(v * mult), nil
+}
+
+// FormatSize formats n bytes in kB or MB.
+func FormatSize(n int64) string {
+	switch {
+	case n >= 1e6:
+		return strconv.FormatFloat(float64(n)/1e6, 'f', 2, 64) + " MB"
+	case n >= 1e3:
+		return strconv.FormatFloat(float64(n)/1e3, 'f', 1, 64) + " kB"
+	}
+	return strconv.FormatInt(n, 10) + " B"
+}
+
+// WriteReport writes a table of the file sizes, largest transfer first, and
+// of the budgets.
+func WriteReport(w io.Writer, sizes []Size, budgets []Budget) error {
+	sizes = slices.Clone(sizes)
+	slices.SortStableFunc(sizes, func(a, b Size) int { return cmp.Compare(b.Transfer(), a.Transfer()) })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "raw\tgzip\tbrotli\t\t")
+	var total Size
+	for _, s := range sizes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t\t%s\n", FormatSize(s.Raw), optionalSize(s.Gzip), optionalSize(s.Brotli), s.Path)
+		total.Raw += s.Raw
+		total.Gzip += s.Gzip
+		total.Brotli += s.Brotli
+	}
+	fmt.Fprintf(tw, "%s\t\t\t\t%s\n", FormatSize(total.Raw), fmt.Sprintf("%d files", len(sizes)))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(budgets) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "budget\tfiles\ttransfer\tmax\t")
+	for _, b := range budgets {
+		status := "ok"
+		if b.Exceeded() {
+			status = "OVER BUDGET"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", b.Pattern, b.Files, FormatSize(b.Total), FormatSize(b.Max), status)
+	}
+	return tw.Flush()
+}
+
+func optionalSize(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return FormatSize(n)
+}
+
+// Prune removes the files of the previous build, listed in its manifest prev,
+// that the current manifest m no longer has: hashed files and their
+// precompressed siblings. Files the manifests do not list are never touched,
+// and a nil prev removes nothing. It returns the number of files removed.
+func Prune(dir string, prev, m *assets.Manifest) (int, error) {
+	if prev == nil {
+		return 0, nil
+	}
+	keep := make(map[string]bool)
+	for _, p := range m.Paths() {
+		keep[m.Path(p)] = true
+	}
+	removed := 0
+	for _, p := range prev.Paths() {
+		hashed := prev.Path(p)
+		if keep[hashed] || !filepath.IsLocal(filepath.FromSlash(hashed)) {
+			continue
+		}
+		for _, name := range []string{hashed, hashed + ".gz", hashed + ".br"} {
+			err := os.Remove(filepath.Join(dir, filepath.FromSlash(name)))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// CheckOutput returns an error when the build output is the static directory
+// or lies inside it, where fingerprinting and pruning would work on the
+// sources.
+func CheckOutput(static, output string) error {
+	s, err := filepath.Abs(static)
+	if err != nil {
+		return err
+	}
+	o, err := filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(s, o); s == o || err == nil && filepath.IsLocal(rel) {
+		return fmt.Errorf("output %s is inside the static directory %s", output, static)
+	}
+	return nil
+}
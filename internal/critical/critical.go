@@ -0,0 +1,293 @@
+// Package critical extracts the CSS a page needs for its first paint, so it
+// can be inlined in the document head while the full stylesheet loads
+// without blocking rendering.
+package critical
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// BelowFold is the attribute marking the first element below the fold.
+// Elements from it on, in document order, are left out of the extraction;
+// without one the whole page counts.
+const BelowFold = "data-below-fold"
+
+// MaxSize is about what the first round trip of a new connection carries.
+// Critical CSS above it delays the first paint it is meant to speed up.
+const MaxSize = 14 * 1024
+
+// Extract returns the rules of css that apply to the pages, HTML documents,
+// above their fold. Style rules keep only their matching selectors, and
+// custom properties, @property, @keyframes and @font-face rules are kept when
+// a kept declaration uses them. Layer order is declared up front, so the full
+// stylesheet loaded later cascades the same way.
+func Extract(css []byte, pages ...[]byte) ([]byte, error) {
+	var elems []*html.Node
+	for _, page := range pages {
+		doc, err := html.Parse(bytes.NewReader(page))
+		if err != nil {
+			return nil, err
+		}
+		elems = aboveFold(doc, elems)
+	}
+
+	rules := parseCSS(string(css))
+	layers := layerNames(rules)
+	rules = filter(rules, elems)
+
+	u := newUsage(elems)
+	u.collect(rules)
+	rules = prune(rules, u)
+
+	var sb strings.Builder
+	if len(layers) > 0 {
+		sb.WriteString("@layer " + strings.Join(layers, ",") + ";")
+	}
+	writeCSS(&sb, rules)
+	return []byte(sb.String()), nil
+}
+
+// aboveFold appends the elements of doc before the first BelowFold one.
+func aboveFold(doc *html.Node, elems []*html.Node) []*html.Node {
+	done := false
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil && !done; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if _, ok := attr(c, BelowFold); ok {
+				done = true
+				return
+			}
+			elems = append(elems, c)
+			walk(c)
+		}
+	}
+	walk(doc)
+	return elems
+}
+
+// layerNames returns the top level cascade layers of rules, in the order
+// they are first declared.
+func layerNames(rules []*rule) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		if r.atName() != "layer" {
+			continue
+		}
+		for _, name := range strings.Split(strings.TrimSpace(r.prelude[len("@layer"):]), ",") {
+			if name = strings.TrimSpace(name); name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// filter keeps the style rules matching one of elems, and the grouping rules
+// left with children. @layer statements are dropped, Extract declares them.
+func filter(rules []*rule, elems []*html.Node) []*rule {
+	var out []*rule
+	for _, r := range rules {
+		name := r.atName()
+		switch {
+		case !r.block:
+			if name != "layer" {
+				out = append(out, r)
+			}
+		case grouping[name]:
+			if children := filter(r.children, elems); len(children) > 0 {
+				out = append(out, &rule{prelude: r.prelude, children: children, block: true})
+			}
+		case name != "":
+			out = append(out, r)
+		default:
+			var kept []string
+			for _, sel := range splitTopLevel(r.prelude, ',') {
+				sel = strings.TrimSpace(sel)
+				compounds := parseSelector(sel)
+				for _, n := range elems {
+					if matches(compounds, n) {
+						kept = append(kept, sel)
+						break
+					}
+				}
+			}
+			if len(kept) > 0 {
+				out = append(out, &rule{prelude: strings.Join(kept, ","), body: r.body, block: true})
+			}
+		}
+	}
+	return out
+}
+
+var (
+	varRef   = regexp.MustCompile(`var\(\s*(--[\w-]+)`)
+	wordChar = regexp.MustCompile(`[\w-]+`)
+)
+
+// usage records what the kept declarations refer to.
+type usage struct {
+	vars   map[string]bool
+	custom map[string][]string // custom property to the values it is given
+	values []string            // values of the declarations in use
+}
+
+// newUsage starts from the inline styles of elems, which may use variables.
+func newUsage(elems []*html.Node) *usage {
+	u := &usage{vars: make(map[string]bool), custom: make(map[string][]string)}
+	for _, n := range elems {
+		if style, ok := attr(n, "style"); ok {
+			u.use(style)
+		}
+	}
+	return u
+}
+
+func (u *usage) use(value string) {
+	u.values = append(u.values, value)
+	for _, m := range varRef.FindAllStringSubmatch(value, -1) {
+		u.vars[m[1]] = true
+	}
+}
+
+// collect walks the style rules, then follows custom properties to the ones
+// their values use until no new one turns up.
+func (u *usage) collect(rules []*rule) {
+	u.walk(rules)
+	for done := make(map[string]bool); ; {
+		grown := false
+		for name := range u.vars {
+			if done[name] {
+				continue
+			}
+			done[name], grown = true, true
+			for _, v := range u.custom[name] {
+				u.use(v)
+			}
+		}
+		if !grown {
+			return
+		}
+	}
+}
+
+func (u *usage) walk(rules []*rule) {
+	for _, r := range rules {
+		switch {
+		case r.children != nil:
+			u.walk(r.children)
+		case r.block && r.atName() == "":
+			for _, d := range declarations(r.body) {
+				if strings.HasPrefix(d.property, "--") {
+					u.custom[d.property] = append(u.custom[d.property], d.value)
+				} else {
+					u.use(d.value)
+				}
+			}
+		}
+	}
+}
+
+// used reports whether name appears as a whole word in a value in use.
+func (u *usage) used(name string) bool {
+	for _, v := range u.values {
+		for _, w := range wordChar.FindAllString(v, -1) {
+			if w == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// prune drops the unused custom properties, @property, @keyframes and
+// @font-face rules, and the rules left empty.
+func prune(rules []*rule, u *usage) []*rule {
+	var out []*rule
+	for _, r := range rules {
+		switch name := r.atName(); {
+		case !r.block:
+			out = append(out, r)
+		case keyframes[name]:
+			if _, id, _ := strings.Cut(r.prelude, " "); u.used(strings.Trim(strings.TrimSpace(id), `"'`)) {
+				out = append(out, r)
+			}
+		case r.children != nil:
+			if children := prune(r.children, u); len(children) > 0 {
+				out = append(out, &rule{prelude: r.prelude, children: children, block: true})
+			}
+		case name == "property":
+			if u.vars[strings.TrimSpace(r.prelude[len("@property"):])] {
+				out = append(out, r)
+			}
+		case name == "font-face":
+			if u.usedFamily(r.body) {
+				out = append(out, r)
+			}
+		case name != "":
+			out = append(out, r)
+		default:
+			var kept []declaration
+			for _, d := range declarations(r.body) {
+				if !strings.HasPrefix(d.property, "--") || u.vars[d.property] {
+					kept = append(kept, d)
+				}
+			}
+			if len(kept) > 0 {
+				out = append(out, &rule{prelude: r.prelude, body: joinDeclarations(kept), block: true})
+			}
+		}
+	}
+	return out
+}
+
+// usedFamily reports whether the font-family of a @font-face body is named in
+// a value in use.
+func (u *usage) usedFamily(body string) bool {
+	for _, d := range declarations(body) {
+		if strings.EqualFold(d.property, "font-family") {
+			family := strings.Trim(d.value, `"' `)
+			for _, v := range u.values {
+				if strings.Contains(v, family) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return true
+}
+
+var cssURL = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+?)(['"]?)\s*\)`)
+
+// Rebase rewrites the relative URLs of css, a stylesheet in the directory
+// from, for a copy of it in the directory to. Both are slash separated and
+// relative to the same root, such as the static directory.
+func Rebase(css []byte, from, to string) []byte {
+	return cssURL.ReplaceAllFunc(css, func(m []byte) []byte {
+		sub := cssURL.FindSubmatch(m)
+		u := string(sub[2])
+		if strings.HasPrefix(u, "/") || strings.HasPrefix(u, "#") || strings.Contains(u, ":") {
+			return m
+		}
+		file, suffix := u, ""
+		if i := strings.IndexAny(u, "?#"); i >= 0 {
+			file, suffix = u[:i], u[i:]
+		}
+		rel, err := filepath.Rel(filepath.FromSlash(to), filepath.FromSlash(path.Join(from, file)))
+		if err != nil {
+			return m
+		}
+		return []byte("url(" + string(sub[1]) + filepath.ToSlash(rel) + suffix + string(sub[3]) + ")")
+	})
+}
@@ -0,0 +1,160 @@
+package critical
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const page = `<!DOCTYPE html>
+<html lang="en">
+<head><title>Home</title></head>
+<body>
+	<nav class="flex hover:bg-base-500"><a href="/" class="p-4">Home</a></nav>
+	<main>
+		<h1 class="text-xl font-sans" style="color: var(--accent)">Welcome</h1>
+		<input type="email" class="md:w-1/2">
+	</main>
+	<section data-below-fold class="grid">
+		<p class="below">Later</p>
+	</section>
+</body>
+</html>`
+
+const css = `/*! banner */
+@layer theme, base, components, utilities;
+@layer theme {
+	:root, :host { --font-sans: "Satoshi", sans-serif; --text-xl: 1.25rem; --color-unused: red; --accent: var(--red); --red: #f00; }
+}
+@layer base {
+	*, ::before { box-sizing: border-box }
+	html, :host { font-family: var(--font-sans) }
+	input:where([type="email"]), textarea { border: 1px solid }
+	table { border-collapse: collapse }
+}
+@layer utilities {
+	.flex { display: flex }
+	.grid { display: grid }
+	.below { color: gray }
+	.p-4 { padding: 1rem }
+	.text-xl { font-size: var(--text-xl) }
+	.font-sans { font-family: var(--font-sans); animation: spin 1s }
+	.hover\:bg-base-500:hover { background: blue }
+	@media (min-width: 48rem) { .md\:w-1\/2 { width: 50% } .md\:grid { display: grid } }
+	nav > a + .x, main h1 ~ input { outline: none }
+}
+@property --tw-rotate-x { syntax: "*"; inherits: false }
+@keyframes spin { to { transform: rotate(360deg) } }
+@keyframes ping { to { opacity: 0 } }
+@font-face { font-family: "Satoshi"; src: url(../fonts/satoshi.woff2) }
+@font-face { font-family: "Iosevka"; src: url(../fonts/iosevka.woff2) }
+`
+
+func TestExtract(t *testing.T) {
+	got, err := Extract([]byte(css), []byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(got)
+	for _, want := range []string{
+		"@layer theme,base,components,utilities;",
+		`:root{--font-sans:"Satoshi", sans-serif;--text-xl:1.25rem;--accent:var(--red);--red:#f00}`,
+		"*,::before{box-sizing:border-box}",
+		`input:where([type="email"]){border:1px solid}`,
+		".flex{display:flex}",
+		".p-4{padding:1rem}",
+		`@media (min-width: 48rem){.md\:w-1\/2{width:50%}}`,
+		"main h1 ~ input{outline:none}",
+		"@keyframes spin{to{transform:rotate(360deg)}}",
+		`@font-face{font-family:"Satoshi"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Extract() is missing %s\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{
+		"banner", ":host", "--color-unused", "table", "textarea", ".grid", ".below", "hover", "md\\:grid",
+		"nav > a + .x", "@property", "ping", "Iosevka",
+	} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Extract() kept %s\n%s", unwanted, out)
+		}
+	}
+	if strings.Count(out, "@layer theme,base") != 1 {
+		t.Errorf("Extract() declared the layer order more than once\n%s", out)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	got, err := Extract([]byte(`
+		:root{color:red}
+		a[href^="/"]{x:1}
+		a[href$=".pdf"]{x:2}
+		[data-state~=open]{x:3}
+		li:first-child{x:4}
+		li:last-child{x:5}
+		li:not(.active){x:6}
+		li:is(.active, .gone){x:7}
+		ul>li.active+li{x:8}
+		div li{x:9}
+		p::before{x:10}
+		button:focus-visible{x:11}
+	`), []byte(`<ul data-state="open closed"><li class="active"><a href="/a">a</a></li><li>b</li></ul><p>c</p>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `:root{color:red}a[href^="/"]{x:1}[data-state~=open]{x:3}li:first-child{x:4}li:last-child{x:5}li:not(.active){x:6}li:is(.active, .gone){x:7}ul>li.active+li{x:8}p::before{x:10}`
+	if string(got) != want {
+		t.Errorf("Extract() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRebase(t *testing.T) {
+	got := Rebase([]byte(`src:url(../fonts/a.woff2)format("woff2"),url("../img/b.svg?v=2#x");background:url(data:image/png;base64,AA),url(/static/c.png),url(#clip)`), "css", "css/critical")
+	want := `src:url(../../fonts/a.woff2)format("woff2"),url("../../img/b.svg?v=2#x");background:url(data:image/png;base64,AA),url(/static/c.png),url(#clip)`
+	if string(got) != want {
+		t.Errorf("Rebase() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	p, err := ParsePage("profile", "pages/profile.Main(profile.Sample)")
+	if err != nil || p.Dir != "pages/profile" || p.Expr != "profile.Main(profile.Sample)" {
+		t.Errorf("ParsePage() = %+v, %v", p, err)
+	}
+	for _, spec := range []string{"", "Main()", "pages/home", "pages/.Main()", "pages/home."} {
+		if _, err := ParsePage("x", spec); err == nil {
+			t.Errorf("ParsePage(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not on PATH")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, _ := ParsePage("home", "pages/home.Main()")
+	html, err := Render(context.Background(), root, "library", []Page{home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html["home"]), `id="alerts"`) {
+		t.Errorf("Render() home =\n%s", html["home"])
+	}
+	if matches, _ := filepath.Glob(filepath.Join(root, "_hgmx_critical*")); len(matches) > 0 {
+		t.Errorf("Render() left %v behind", matches)
+		for _, m := range matches {
+			os.RemoveAll(m)
+		}
+	}
+}
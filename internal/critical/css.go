@@ -0,0 +1,260 @@
+package critical
+
+import (
+	"strings"
+)
+
+// rule is a node of a parsed stylesheet.
+type rule struct {
+	prelude  string  // selector list, or at-rule with its name
+	body     string  // declarations; nil children and empty body for statements
+	children []*rule // rules of grouping at-rules such as @media
+	block    bool    // has a {} block, unlike @layer a, b;
+}
+
+// atName returns the lower case name of an at-rule, or "" for style rules.
+func (r *rule) atName() string {
+	if !strings.HasPrefix(r.prelude, "@") {
+		return ""
+	}
+	name := r.prelude[1:]
+	if i := strings.IndexAny(name, " \t\n\r({;\"'"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(name)
+}
+
+// grouping at-rules hold rules rather than declarations.
+var grouping = map[string]bool{
+	"media": true, "supports": true, "layer": true, "container": true,
+	"document": true, "scope": true, "starting-style": true,
+}
+
+// keyframes at-rules hold rules too, but their selectors are not matched.
+var keyframes = map[string]bool{"keyframes": true, "-webkit-keyframes": true}
+
+// parseCSS splits css into rules. It only finds the structure: blocks,
+// statements and, inside grouping at-rules, nested rules.
+func parseCSS(css string) []*rule {
+	var rules []*rule
+	i := 0
+	for i < len(css) {
+		i = skipSpaceAndComments(css, i)
+		if i >= len(css) {
+			break
+		}
+		if css[i] == '}' {
+			// stray closing brace
+			i++
+			continue
+		}
+		end := scanUntil(css, i, "{;}")
+		prelude := strings.TrimSpace(stripComments(css[i:end]))
+		if end >= len(css) || css[end] != '{' {
+			if prelude != "" {
+				rules = append(rules, &rule{prelude: prelude})
+			}
+			i = end + 1
+			continue
+		}
+		close := matchBrace(css, end)
+		r := &rule{prelude: prelude, block: true}
+		body := css[end+1 : close]
+		if name := r.atName(); grouping[name] || keyframes[name] {
+			r.children = parseCSS(body)
+		} else {
+			r.body = joinDeclarations(declarations(stripComments(body)))
+		}
+		rules = append(rules, r)
+		i = close + 1
+	}
+	return rules
+}
+
+// writeCSS writes rules back, minified.
+func writeCSS(sb *strings.Builder, rules []*rule) {
+	for _, r := range rules {
+		sb.WriteString(r.prelude)
+		switch {
+		case !r.block:
+			sb.WriteString(";")
+		case r.children != nil:
+			sb.WriteString("{")
+			writeCSS(sb, r.children)
+			sb.WriteString("}")
+		default:
+			sb.WriteString("{" + r.body + "}")
+		}
+	}
+}
+
+// declaration is one property: value pair of a rule body.
+type declaration struct {
+	property string
+	value    string
+}
+
+// declarations splits a rule body on its top level semicolons. Nested rules
+// are kept whole, as a declaration without property.
+func declarations(body string) []declaration {
+	var decls []declaration
+	for i := 0; i < len(body); {
+		end := scanUntil(body, i, ";{")
+		if end < len(body) && body[end] == '{' {
+			end = matchBrace(body, end) + 1
+			decls = append(decls, declaration{value: strings.TrimSpace(body[i:min(end, len(body))])})
+			i = end
+			continue
+		}
+		part := strings.TrimSpace(body[i:min(end, len(body))])
+		if prop, value, ok := strings.Cut(part, ":"); ok {
+			decls = append(decls, declaration{strings.TrimSpace(prop), strings.TrimSpace(value)})
+		} else if part != "" {
+			decls = append(decls, declaration{value: part})
+		}
+		i = end + 1
+	}
+	return decls
+}
+
+func joinDeclarations(decls []declaration) string {
+	parts := make([]string, len(decls))
+	for i, d := range decls {
+		if d.property == "" {
+			parts[i] = d.value
+			continue
+		}
+		parts[i] = d.property + ":" + d.value
+	}
+	return strings.Join(parts, ";")
+}
+
+// splitTopLevel splits s on sep outside strings, parentheses and brackets, as
+// in a selector list.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			i++
+		case '"', '\'':
+			i = skipString(s, i)
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// scanUntil returns the index of the first of stops in css from i that is
+// outside strings, comments and parentheses, or len(css).
+func scanUntil(css string, i int, stops string) int {
+	depth := 0
+	for ; i < len(css); i++ {
+		c := css[i]
+		switch {
+		case c == '\\':
+			i++
+		case c == '"' || c == '\'':
+			i = skipString(css, i)
+		case c == '/' && i+1 < len(css) && css[i+1] == '*':
+			i = skipComment(css, i) - 1
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth <= 0 && strings.IndexByte(stops, c) >= 0:
+			return i
+		}
+	}
+	return len(css)
+}
+
+// matchBrace returns the index of the brace closing the one at open, or the
+// end of css when it is unclosed.
+func matchBrace(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); i++ {
+		c := css[i]
+		switch {
+		case c == '\\':
+			i++
+		case c == '"' || c == '\'':
+			i = skipString(css, i)
+		case c == '/' && i+1 < len(css) && css[i+1] == '*':
+			i = skipComment(css, i) - 1
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(css)
+}
+
+// skipString returns the index of the quote closing the string at i.
+func skipString(s string, i int) int {
+	quote := s[i]
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return len(s)
+}
+
+// skipComment returns the index after the comment at i.
+func skipComment(s string, i int) int {
+	if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+		return i + 2 + end + 2
+	}
+	return len(s)
+}
+
+func skipSpaceAndComments(s string, i int) int {
+	for i < len(s) {
+		switch {
+		case strings.IndexByte(" \t\n\r\f", s[i]) >= 0:
+			i++
+		case strings.HasPrefix(s[i:], "/*"):
+			i = skipComment(s, i)
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func stripComments(s string) string {
+	if !strings.Contains(s, "/*") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\'':
+			end := min(skipString(s, i), len(s)-1)
+			sb.WriteString(s[i : end+1])
+			i = end
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			i = skipComment(s, i) - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+package critical
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Page is a page to extract critical CSS for: a Go expression of its
+// component, whose package is a directory of the views, such as
+// "pages/home.Main()" or "pages/profile.Main(profile.Sample)".
+type Page struct {
+	Name string
+	Dir  string // package directory relative to the views
+	Expr string // expression, qualified with the package name
+}
+
+// ParsePage parses the component expression of the page name.
+func ParsePage(name, spec string) (Page, error) {
+	open := strings.IndexByte(spec, '(')
+	if open < 0 {
+		open = len(spec)
+	}
+	dot := strings.LastIndexByte(spec[:open], '.')
+	slash := strings.LastIndexByte(spec[:max(dot, 0)], '/')
+	if dot < 0 || dot == slash+1 || dot == len(spec)-1 {
+		return Page{}, fmt.Errorf("page %s: %q is not a component expression such as pages/home.Main()", name, spec)
+	}
+	return Page{Name: name, Dir: spec[:dot], Expr: spec[slash+1:]}, nil
+}
+
+var program = template.Must(template.New("main").Parse(`// Code generated by hgmx css critical. DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/a-h/templ"
+
+	views {{ printf "%q" .Views }}
+{{- range .Imports }}
+	{{ printf "%q" . }}
+{{- end }}
+)
+
+func main() {
+	pages := map[string]templ.Component{
+{{- range .Pages }}
+		{{ printf "%q" .Name }}: {{ .Expr }},
+{{- end }}
+	}
+	for name, page := range pages {
+		f, err := os.Create(filepath.Join(os.Args[1], name+".html"))
+		if err != nil {
+			panic(err)
+		}
+		if err := views.Full(page).Render(context.Background(), f); err != nil {
+			panic(err)
+		}
+		if err := f.Close(); err != nil {
+			panic(err)
+		}
+	}
+}
+`))
+
+// Program returns the source of a program rendering pages inside views.Full
+// of the views package at the import path views. It writes name.html for
+// every page to the directory given as its argument.
+func Program(views string, pages []Page) ([]byte, error) {
+	var imports []string
+	names := make(map[string]string)
+	for _, p := range pages {
+		pkg := views + "/" + p.Dir
+		if other, ok := names[path.Base(pkg)]; ok && other != pkg {
+			return nil, fmt.Errorf("page %s: packages %s and %s have the same name", p.Name, other, pkg)
+		}
+		if !slices.Contains(imports, pkg) {
+			imports = append(imports, pkg)
+		}
+		names[path.Base(pkg)] = pkg
+	}
+	slices.Sort(imports)
+	var b bytes.Buffer
+	err := program.Execute(&b, map[string]any{"Views": views, "Imports": imports, "Pages": pages})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(b.Bytes())
+}
+
+// Render renders pages with the views of the Go module in root, by running
+// the program of Program inside the module, and returns their HTML by name.
+func Render(ctx context.Context, root, views string, pages []Page) (map[string][]byte, error) {
+	module, err := modulePath(root)
+	if err != nil {
+		return nil, err
+	}
+	src, err := Program(path.Join(module, filepath.ToSlash(filepath.Clean(views))), pages)
+	if err != nil {
+		return nil, err
+	}
+
+	// a leading underscore keeps the program out of ./... patterns
+	dir, err := os.MkdirTemp(root, "_hgmx_critical")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0o644); err != nil {
+		return nil, err
+	}
+	out := filepath.Join(dir, "out")
+	if err := os.Mkdir(out, 0o755); err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	run := exec.CommandContext(ctx, "go", "run", "./"+filepath.Base(dir), out)
+	run.Dir, run.Stdout, run.Stderr = root, &stderr, &stderr
+	if err := run.Run(); err != nil {
+		return nil, fmt.Errorf("rendering pages: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	html := make(map[string][]byte)
+	for _, p := range pages {
+		if html[p.Name], err = os.ReadFile(filepath.Join(out, p.Name+".html")); err != nil {
+			return nil, err
+		}
+	}
+	return html, nil
+}
+
+// modulePath returns the module path declared in root's go.mod.
+func modulePath(root string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if f := strings.Fields(line); len(f) >= 2 && f[0] == "module" {
+			if p, err := strconv.Unquote(f[1]); err == nil {
+				return p, nil
+			}
+			return f[1], nil
+		}
+	}
+	return "", fmt.Errorf("%s: no module directive", filepath.Join(root, "go.mod"))
+}
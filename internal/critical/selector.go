@@ -0,0 +1,336 @@
+package critical
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// compound is a compound selector, such as a.btn[href]:hover, and the
+// combinator joining it to the compound on its left.
+type compound struct {
+	combinator byte // ' ', '>', '+' or '~'; 0 for the leftmost
+	tag        string
+	simple     []simple
+}
+
+// simple is one id, class, attribute or pseudo-class test.
+type simple struct {
+	kind  byte // '#', '.', '[', ':'
+	name  string
+	op    string // attribute operator, e.g. "^="
+	value string
+	args  string // pseudo-class arguments, without the parentheses
+}
+
+// parseSelector splits a complex selector into compounds. Pseudo-elements are
+// dropped: ::before applies wherever its element does.
+func parseSelector(sel string) []compound {
+	var out []compound
+	cur := compound{}
+	pending := byte(0)
+	flush := func() {
+		if cur.tag != "" || len(cur.simple) > 0 {
+			cur.combinator = pending
+			if len(out) == 0 {
+				cur.combinator = 0
+			}
+			out = append(out, cur)
+			pending = ' '
+		}
+		cur = compound{}
+	}
+	for i := 0; i < len(sel); {
+		c := sel[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			if cur.tag != "" || len(cur.simple) > 0 {
+				flush()
+			}
+			i++
+		case c == '>' || c == '+' || c == '~':
+			flush()
+			pending = c
+			i++
+		case c == '*':
+			cur.tag = "*"
+			i++
+		case c == '#' || c == '.':
+			name, n := readIdent(sel[i+1:])
+			cur.simple = append(cur.simple, simple{kind: c, name: name})
+			i += 1 + n
+		case c == '[':
+			end := i + 1
+			for end < len(sel) && sel[end] != ']' {
+				if sel[end] == '"' || sel[end] == '\'' {
+					end = skipString(sel, end)
+				}
+				end++
+			}
+			cur.simple = append(cur.simple, parseAttr(sel[i+1:min(end, len(sel))]))
+			i = end + 1
+		case c == ':':
+			element := strings.HasPrefix(sel[i:], "::")
+			if element {
+				i++
+			}
+			name, n := readIdent(sel[i+1:])
+			i += 1 + n
+			var args string
+			if i < len(sel) && sel[i] == '(' {
+				end := scanUntil(sel, i+1, ")")
+				args = sel[i+1 : min(end, len(sel))]
+				i = end + 1
+			}
+			name = strings.ToLower(name)
+			if !element && !legacyElements[name] {
+				cur.simple = append(cur.simple, simple{kind: ':', name: name, args: args})
+			}
+		default:
+			name, n := readIdent(sel[i:])
+			if n == 0 {
+				// unknown syntax: keep the selector, it is cheaper than missing a rule
+				return nil
+			}
+			cur.tag = strings.ToLower(name)
+			i += n
+		}
+	}
+	flush()
+	return out
+}
+
+// legacyElements are pseudo-elements written with one colon.
+var legacyElements = map[string]bool{"before": true, "after": true, "first-line": true, "first-letter": true}
+
+// readIdent reads a CSS identifier, unescaping it: Tailwind writes
+// hover:bg-base-500 as hover\:bg-base-500.
+func readIdent(s string) (string, int) {
+	var sb strings.Builder
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			j := i + 1
+			for j < len(s) && j < i+7 && isHex(s[j]) {
+				j++
+			}
+			if j > i+1 {
+				r, _ := strconv.ParseUint(s[i+1:j], 16, 32)
+				sb.WriteRune(rune(r))
+				if j < len(s) && s[j] == ' ' {
+					j++
+				}
+				i = j
+				continue
+			}
+			sb.WriteByte(s[i+1])
+			i += 2
+		case c == '-' || c == '_' || c >= 0x80 || c >= '0' && c <= '9' || c|0x20 >= 'a' && c|0x20 <= 'z':
+			sb.WriteByte(c)
+			i++
+		default:
+			return sb.String(), i
+		}
+	}
+	return sb.String(), i
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c|0x20 >= 'a' && c|0x20 <= 'f'
+}
+
+func parseAttr(s string) simple {
+	s = strings.TrimSpace(s)
+	a := simple{kind: '['}
+	i := strings.IndexAny(s, "=~|^$*")
+	if i < 0 {
+		a.name, _ = readIdent(s)
+		a.name = strings.ToLower(a.name)
+		return a
+	}
+	a.name, _ = readIdent(strings.TrimSpace(s[:i]))
+	a.name = strings.ToLower(a.name)
+	if s[i] == '=' {
+		a.op = "="
+	} else {
+		a.op = s[i : i+2]
+		i++
+	}
+	v := strings.TrimSpace(s[i+1:])
+	// drop a trailing case flag: [type=a i]
+	if n := len(v); n > 2 && v[n-2] == ' ' && (v[n-1] == 'i' || v[n-1] == 's') {
+		v = strings.TrimSpace(v[:n-2])
+	}
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') {
+		v = v[1 : len(v)-1]
+	} else {
+		v, _ = readIdent(v)
+	}
+	a.value = v
+	return a
+}
+
+// interactive pseudo-classes need user input, so never apply at first paint.
+var interactive = map[string]bool{
+	"hover": true, "focus": true, "focus-visible": true, "focus-within": true,
+	"active": true, "visited": true, "target": true, "user-invalid": true, "user-valid": true,
+}
+
+// matches reports whether the complex selector matches n. Selectors it cannot
+// parse or pseudo-classes it does not know match, so the extracted CSS errs
+// on the side of including a rule.
+func matches(sel []compound, n *html.Node) bool {
+	if len(sel) == 0 {
+		return true
+	}
+	last := sel[len(sel)-1]
+	if !matchCompound(last, n) {
+		return false
+	}
+	rest := sel[:len(sel)-1]
+	if len(rest) == 0 {
+		return true
+	}
+	switch last.combinator {
+	case '>':
+		p := parentElement(n)
+		return p != nil && matches(rest, p)
+	case '+':
+		p := prevElement(n)
+		return p != nil && matches(rest, p)
+	case '~':
+		for p := prevElement(n); p != nil; p = prevElement(p) {
+			if matches(rest, p) {
+				return true
+			}
+		}
+		return false
+	default:
+		for p := parentElement(n); p != nil; p = parentElement(p) {
+			if matches(rest, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func matchCompound(c compound, n *html.Node) bool {
+	if c.tag != "" && c.tag != "*" && c.tag != n.Data {
+		return false
+	}
+	for _, s := range c.simple {
+		if !matchSimple(s, n) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchSimple(s simple, n *html.Node) bool {
+	switch s.kind {
+	case '#':
+		v, _ := attr(n, "id")
+		return v == s.name
+	case '.':
+		v, _ := attr(n, "class")
+		for _, class := range strings.Fields(v) {
+			if class == s.name {
+				return true
+			}
+		}
+		return false
+	case '[':
+		v, ok := attr(n, s.name)
+		if !ok {
+			return false
+		}
+		switch s.op {
+		case "":
+			return true
+		case "=":
+			return v == s.value
+		case "~=":
+			for _, f := range strings.Fields(v) {
+				if f == s.value {
+					return true
+				}
+			}
+			return false
+		case "|=":
+			return v == s.value || strings.HasPrefix(v, s.value+"-")
+		case "^=":
+			return s.value != "" && strings.HasPrefix(v, s.value)
+		case "$=":
+			return s.value != "" && strings.HasSuffix(v, s.value)
+		case "*=":
+			return s.value != "" && strings.Contains(v, s.value)
+		}
+		return true
+	}
+
+	switch {
+	case interactive[s.name]:
+		return false
+	case s.name == "root":
+		return n.Parent != nil && n.Parent.Type == html.DocumentNode
+	case s.name == "host":
+		return false
+	case s.name == "is" || s.name == "where" || s.name == "matches" || s.name == "-webkit-any":
+		for _, arg := range splitTopLevel(s.args, ',') {
+			if matches(parseSelector(strings.TrimSpace(arg)), n) {
+				return true
+			}
+		}
+		return false
+	case s.name == "not":
+		for _, arg := range splitTopLevel(s.args, ',') {
+			if sel := parseSelector(strings.TrimSpace(arg)); len(sel) == 1 && matches(sel, n) {
+				return false
+			}
+		}
+		return true
+	case s.name == "first-child":
+		return prevElement(n) == nil
+	case s.name == "last-child":
+		return nextElement(n) == nil
+	}
+	return true
+}
+
+func attr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func parentElement(n *html.Node) *html.Node {
+	if p := n.Parent; p != nil && p.Type == html.ElementNode {
+		return p
+	}
+	return nil
+}
+
+func prevElement(n *html.Node) *html.Node {
+	for p := n.PrevSibling; p != nil; p = p.PrevSibling {
+		if p.Type == html.ElementNode {
+			return p
+		}
+	}
+	return nil
+}
+
+func nextElement(n *html.Node) *html.Node {
+	for p := n.NextSibling; p != nil; p = p.NextSibling {
+		if p.Type == html.ElementNode {
+			return p
+		}
+	}
+	return nil
+}
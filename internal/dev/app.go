@@ -0,0 +1,126 @@
+package dev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// App builds and runs the user's server, replacing the running process on
+// each Restart.
+type App struct {
+	Dir     string   // module directory to build in
+	Package string   // package to build, e.g. "." or "./cmd/server"
+	Args    []string // arguments passed to the app
+	Addr    string   // address the app listens on, waited for after start
+	Env     []string // extra environment, e.g. PORT
+	Bin     string   // path to build the binary to
+	Stdout  io.Writer
+	Stderr  io.Writer
+
+	// ReadyTimeout bounds the wait for Addr to accept connections, default 10s.
+	ReadyTimeout time.Duration
+
+	mu   sync.Mutex
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// Restart builds the app, and only if that succeeds stops the running process
+// and starts the new one, returning once it accepts connections on Addr.
+func (a *App) Restart(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	bin := a.Bin
+	if runtime.GOOS == "windows" && filepath.Ext(bin) != ".exe" {
+		bin += ".exe"
+	}
+	build := exec.CommandContext(ctx, "go", "build", "-o", bin, a.Package)
+	build.Dir = a.Dir
+	build.Stdout, build.Stderr = a.Stderr, a.Stderr
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("go build %s: %w", a.Package, err)
+	}
+
+	a.stop()
+
+	cmd := exec.Command(bin, a.Args...)
+	cmd.Dir = a.Dir
+	cmd.Env = append(os.Environ(), a.Env...)
+	cmd.Stdout, cmd.Stderr = a.Stdout, a.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+	a.cmd, a.done = cmd, done
+
+	if a.Addr == "" {
+		return nil
+	}
+	timeout := a.ReadyTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return waitReady(ctx, a.Addr, done, timeout)
+}
+
+// Stop ends the running process, if any.
+func (a *App) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stop()
+}
+
+// stop interrupts the process and kills it if it has not exited after five
+// seconds.
+func (a *App) stop() {
+	if a.cmd == nil {
+		return
+	}
+	if runtime.GOOS == "windows" || a.cmd.Process.Signal(os.Interrupt) != nil {
+		a.cmd.Process.Kill()
+	}
+	select {
+	case <-a.done:
+	case <-time.After(5 * time.Second):
+		a.cmd.Process.Kill()
+		<-a.done
+	}
+	a.cmd, a.done = nil, nil
+}
+
+// waitReady polls addr until it accepts a connection, the process exits or
+// timeout passes.
+func waitReady(ctx context.Context, addr string, exited <-chan struct{}, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	tick := time.NewTicker(50 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		if conn, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+			conn.Close()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-exited:
+			return errors.New("app exited before listening on " + addr)
+		case <-deadline:
+			return fmt.Errorf("app not listening on %s after %s", addr, timeout)
+		case <-tick.C:
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package dev
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const cssOutput = "views/static/css/main.min.css"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		changed []string
+		want    Plan
+	}{
+		{[]string{"views/home.templ", "views/home.templ"}, Plan{Templ: []string{"views/home.templ"}, Restart: true, Reload: ReloadPage}},
+		{[]string{"main.go"}, Plan{Restart: true, Reload: ReloadPage}},
+		{[]string{"views/home_templ.go", "main_test.go"}, Plan{}},
+		{[]string{cssOutput}, Plan{Reload: ReloadCSS}},
+		{[]string{"views/static/css/main.css"}, Plan{}},
+		{[]string{cssOutput, "main.go"}, Plan{Restart: true, Reload: ReloadPage}},
+		{[]string{"views/static/js/app.js", cssOutput}, Plan{Reload: ReloadPage}},
+		{[]string{"README.md"}, Plan{}},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.changed, cssOutput); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Classify(%q) = %+v, want %+v", tt.changed, got, tt.want)
+		}
+	}
+}
+
+// recorder is a Runner whose steps append to calls.
+func recorder(calls *[]string, restartErr error) *Runner {
+	return &Runner{
+		CSSOutput: cssOutput,
+		Debounce:  5 * time.Millisecond,
+		Log:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Generate: func(ctx context.Context, files []string) error {
+			*calls = append(*calls, "generate "+strings.Join(files, " "))
+			return nil
+		},
+		Safelist: func() error {
+			*calls = append(*calls, "safelist")
+			return nil
+		},
+		Restart: func(ctx context.Context) error {
+			*calls = append(*calls, "restart")
+			return restartErr
+		},
+		Reload: func(kind string) { *calls = append(*calls, "reload "+kind) },
+	}
+}
+
+func TestRunner(t *testing.T) {
+	var calls []string
+	r := recorder(&calls, nil)
+	events := make(chan string)
+	done := make(chan error)
+	go func() { done <- r.Run(context.Background(), events) }()
+
+	events <- "views/home.templ"
+	events <- "views/home_templ.go"
+	events <- "main.go"
+	time.Sleep(50 * time.Millisecond)
+	events <- cssOutput
+	close(events)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"generate views/home.templ", "safelist", "restart", "reload page", "reload css"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestRunnerFailedRestart(t *testing.T) {
+	var calls []string
+	recorder(&calls, errors.New("build failed")).Apply(context.Background(), Classify([]string{"main.go"}, cssOutput))
+	if want := []string{"safelist", "restart"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q without a reload", calls, want)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.URL.Path == "/fragment" {
+			io.WriteString(w, "<li>item</li>")
+			return
+		}
+		io.WriteString(w, "<html><body><h1>home</h1></body></html>")
+	}))
+	target, _ := url.Parse(app.URL)
+	reloader := NewReloader(target)
+	proxy := httptest.NewServer(reloader)
+	defer proxy.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(proxy.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp, string(b)
+	}
+
+	resp, body := get("/")
+	if want := "<h1>home</h1>" + scriptTag + "</body>"; !strings.Contains(body, want) {
+		t.Errorf("page = %q, want the script before </body>", body)
+	}
+	if resp.Header.Get("Content-Length") != strconv.Itoa(len(body)) {
+		t.Errorf("Content-Length = %s, body is %d bytes", resp.Header.Get("Content-Length"), len(body))
+	}
+	if _, body := get("/fragment"); body != "<li>item</li>" {
+		t.Errorf("fragment = %q, want it unchanged", body)
+	}
+	if resp, body := get(ReloadScriptPath); !strings.Contains(resp.Header.Get("Content-Type"), "javascript") || !strings.Contains(body, ReloadPath) {
+		t.Errorf("script = %s %q", resp.Header.Get("Content-Type"), body)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, proxy.URL+ReloadPath, nil)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	go func() {
+		// The subscription starts after the response headers, so publish until
+		// the event is read.
+		for ctx.Err() == nil {
+			reloader.Reload(ReloadCSS)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	lines := bufio.NewScanner(stream.Body)
+	for lines.Scan() && lines.Text() != "event: reload" {
+	}
+	if lines.Scan(); lines.Text() != "data: css" {
+		t.Errorf("event data = %q, want %q", lines.Text(), "data: css")
+	}
+	cancel()
+
+	app.Close()
+	resp, body = get("/")
+	if resp.StatusCode != http.StatusBadGateway || !strings.Contains(body, scriptTag) {
+		t.Errorf("app down = %d %q, want a 502 page that reloads itself", resp.StatusCode, body)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ignored := filepath.Join(dir, "ignored.css")
+	events, err := Watch(ctx, dir, func(p string) bool { return p == ignored })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := func() string {
+		select {
+		case p := <-events:
+			return p
+		case <-time.After(2 * time.Second):
+			t.Fatal("no event")
+			return ""
+		}
+	}
+
+	os.WriteFile(ignored, nil, 0o644)
+	os.WriteFile(filepath.Join(dir, ".hidden"), nil, 0o644)
+	home := filepath.Join(dir, "home.templ")
+	os.WriteFile(home, nil, 0o644)
+	if p := next(); p != home {
+		t.Errorf("event = %q, want %q", p, home)
+	}
+
+	sub := filepath.Join(dir, "pages")
+	os.Mkdir(sub, 0o755)
+	time.Sleep(50 * time.Millisecond)
+	page := filepath.Join(sub, "page.templ")
+	os.WriteFile(page, nil, 0o644)
+	for p := next(); p != page; p = next() {
+		if p != home {
+			t.Fatalf("event = %q, want %q", p, page)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatchVendor(t *testing.T) {
+	dir := t.TempDir()
+	goVendor := filepath.Join(dir, "vendor", "example.com", "lib")
+	scripts := filepath.Join(dir, "static", "scripts", "vendor")
+	for _, d := range []string{goVendor, scripts} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := Watch(ctx, dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the Go vendor directory at the root is skipped
+	os.WriteFile(filepath.Join(goVendor, "lib.go"), nil, 0o644)
+	htmx := filepath.Join(scripts, "htmx.min.js")
+	os.WriteFile(htmx, nil, 0o644)
+	select {
+	case p := <-events:
+		if p != htmx {
+			t.Errorf("event = %q, want %q", p, htmx)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event for the vendored script")
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatchSkipsCreatedDirs(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := Watch(ctx, dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// directories created later are skipped like those found at the start
+	for _, d := range []string{"dist", "node_modules", "_hgmx_critical123", ".cache"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	for _, f := range []string{"dist/app.js", "node_modules/x.js", "_hgmx_critical123/main.go", ".cache/x"} {
+		os.WriteFile(filepath.Join(dir, filepath.FromSlash(f)), nil, 0o644)
+	}
+	home := filepath.Join(dir, "home.templ")
+	os.WriteFile(home, nil, 0o644)
+	select {
+	case p := <-events:
+		if p != home {
+			t.Errorf("event = %q from a skipped directory, want %q", p, home)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event")
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	if err := waitReady(context.Background(), addr, nil, time.Second); err != nil {
+		t.Errorf("waitReady() on a listener = %v", err)
+	}
+	ln.Close()
+
+	exited := make(chan struct{})
+	close(exited)
+	if err := waitReady(context.Background(), addr, exited, time.Second); err == nil {
+		t.Error("waitReady() after the app exited succeeded")
+	}
+}
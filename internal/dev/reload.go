@@ -0,0 +1,102 @@
+package dev
+
+import (
+	"bytes"
+	_ "embed"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nosvagor/hgmx/sse"
+)
+
+// Paths served by the Reloader in front of the app.
+const (
+	ReloadPath       = "/_hgmx/reload"
+	ReloadScriptPath = "/_hgmx/reload.js"
+)
+
+// reloadScript listens on ReloadPath. It is a file rather than an inline
+// script so a nonce based Content-Security-Policy allows it through 'self'.
+//
+//go:embed reload.js
+var reloadScript []byte
+
+// scriptTag is inserted before </body> of every full page.
+const scriptTag = `<script src="` + ReloadScriptPath + `" defer></script>`
+
+// Reloader is a reverse proxy to the app that injects the live reload script
+// into HTML pages and streams reloads to them. Because the script is only ever
+// added by the proxy, nothing of it reaches production.
+type Reloader struct {
+	broker *sse.Broker
+	proxy  *httputil.ReverseProxy
+}
+
+// NewReloader returns a Reloader proxying to target.
+func NewReloader(target *url.URL) *Reloader {
+	r := &Reloader{broker: sse.NewBroker(sse.WithReplay(0))}
+	r.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			pr.Out.Host = pr.In.Host
+			// Only uncompressed responses can be rewritten; the transport
+			// still asks for gzip and decodes it transparently.
+			pr.Out.Header.Del("Accept-Encoding")
+		},
+		ModifyResponse: inject,
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			// The app is restarting or failed to build; this page reloads
+			// itself once it is back.
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusBadGateway)
+			io.WriteString(w, "<!doctype html><title>hgmx dev</title><body><p>Waiting for the app: "+
+				html.EscapeString(err.Error())+"</p>"+scriptTag+"</body>")
+		},
+	}
+	return r
+}
+
+// Reload tells every connected page to reload, kind being ReloadPage or
+// ReloadCSS.
+func (r *Reloader) Reload(kind string) {
+	r.broker.Publish("reload", sse.Event{Name: "reload", Data: kind})
+}
+
+func (r *Reloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case ReloadPath:
+		r.broker.Serve(w, req, "reload")
+	case ReloadScriptPath:
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(reloadScript)
+	default:
+		r.proxy.ServeHTTP(w, req)
+	}
+}
+
+// inject adds the reload script to uncompressed HTML documents. Fragments
+// swapped in by htmx have no </body> and are left alone.
+func inject(resp *http.Response) error {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || resp.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if i := bytes.LastIndex(body, []byte("</body>")); i >= 0 {
+		body = append(body[:i:i], append([]byte(scriptTag), body[i:]...)...)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
@@ -0,0 +1,17 @@
+// Injected by hgmx dev. Reloads the page, or only its stylesheets, when the
+// dev server says the project changed.
+(() => {
+  const source = new EventSource("/_hgmx/reload");
+  source.addEventListener("reload", (event) => {
+    if (event.data !== "css") {
+      location.reload();
+      return;
+    }
+    for (const link of document.querySelectorAll('link[rel="stylesheet"]')) {
+      const url = new URL(link.href);
+      if (url.origin !== location.origin) continue;
+      url.searchParams.set("v", Date.now());
+      link.href = url.href;
+    }
+  });
+})();
@@ -0,0 +1,140 @@
+// Package dev runs the hgmx dev loop: it watches the project, regenerates templ
+// files, restarts the app and tells browsers to reload.
+//
+// The loop is split so each part can be driven on its own: Watch turns file
+// system notifications into paths, a Runner turns batches of paths into build
+// steps, an App rebuilds and restarts the user's server, and a Reloader proxies
+// the app, injecting the live reload script and pushing reloads over SSE.
+package dev
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Reload kinds sent to the browser.
+const (
+	ReloadPage = "page" // reload the whole page
+	ReloadCSS  = "css"  // refetch stylesheets in place
+)
+
+// Plan is what a batch of changed files requires.
+type Plan struct {
+	Templ   []string // .templ files to generate
+	Restart bool     // the app must be rebuilt
+	Reload  string   // ReloadPage, ReloadCSS or "" for nothing
+}
+
+// staticExts are files served as is, which only need a page reload.
+var staticExts = []string{".js", ".html", ".svg", ".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif", ".ico", ".woff", ".woff2", ".webmanifest"}
+
+// Classify returns the plan for changed. cssOutput is the built stylesheet,
+// whose change means Tailwind finished a rebuild; other stylesheets are inputs
+// Tailwind watches itself. Generated and test files are ignored.
+func Classify(changed []string, cssOutput string) Plan {
+	var p Plan
+	for _, name := range changed {
+		switch ext := filepath.Ext(name); {
+		case ext == ".templ":
+			if !slices.Contains(p.Templ, name) {
+				p.Templ = append(p.Templ, name)
+			}
+			p.Restart = true
+		case ext == ".go":
+			if !strings.HasSuffix(name, "_templ.go") && !strings.HasSuffix(name, "_test.go") {
+				p.Restart = true
+			}
+		case ext == ".css":
+			if filepath.Clean(name) == filepath.Clean(cssOutput) && p.Reload == "" {
+				p.Reload = ReloadCSS
+			}
+		case slices.Contains(staticExts, strings.ToLower(ext)):
+			p.Reload = ReloadPage
+		}
+	}
+	if p.Restart {
+		p.Reload = ReloadPage
+	}
+	return p
+}
+
+// Runner carries out the plan for each batch of changes. Every step is a
+// function so the loop can be tested without a compiler or a browser.
+type Runner struct {
+	CSSOutput string        // path of the built stylesheet, see Classify
+	Debounce  time.Duration // quiet period that ends a batch, defaults to 100ms
+	Log       *slog.Logger
+
+	Generate func(ctx context.Context, files []string) error // templ generate
+	Safelist func() error                                    // rewrite safelist.css
+	Restart  func(ctx context.Context) error                 // rebuild and restart the app
+	Reload   func(kind string)                               // notify browsers
+}
+
+// Run reads changed paths until events is closed or ctx is done, running one
+// plan per batch of changes that arrive within Debounce of each other.
+func (r *Runner) Run(ctx context.Context, events <-chan string) error {
+	debounce := r.Debounce
+	if debounce <= 0 {
+		debounce = 100 * time.Millisecond
+	}
+
+	var batch []string
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case name, ok := <-events:
+			if !ok {
+				if len(batch) > 0 {
+					r.Apply(ctx, Classify(batch, r.CSSOutput))
+				}
+				return nil
+			}
+			batch = append(batch, name)
+			timer = time.After(debounce)
+		case <-timer:
+			r.Apply(ctx, Classify(batch, r.CSSOutput))
+			batch, timer = nil, nil
+		}
+	}
+}
+
+// Apply runs the steps of p in order. A failing step is logged and stops the
+// plan, so a broken build keeps the previous app running and the browser is
+// not reloaded onto an error.
+func (r *Runner) Apply(ctx context.Context, p Plan) {
+	log := r.Log
+	if log == nil {
+		log = slog.Default()
+	}
+
+	if len(p.Templ) > 0 && r.Generate != nil {
+		if err := r.Generate(ctx, p.Templ); err != nil {
+			log.Error("templ generate failed", slog.String("error", err.Error()))
+			return
+		}
+	}
+	if p.Restart {
+		if r.Safelist != nil {
+			if err := r.Safelist(); err != nil {
+				log.Warn("Failed to write safelist", slog.String("error", err.Error()))
+			}
+		}
+		if r.Restart != nil {
+			if err := r.Restart(ctx); err != nil {
+				log.Error("App restart failed", slog.String("error", err.Error()))
+				return
+			}
+		}
+	}
+	if p.Reload != "" && r.Reload != nil {
+		log.Debug("Reloading browsers", slog.String("reload", p.Reload))
+		r.Reload(p.Reload)
+	}
+}
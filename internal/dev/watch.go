@@ -0,0 +1,97 @@
+package dev
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// skipDirs are never watched, besides directories starting with a dot or an
+// underscore, which Go tooling ignores too, such as the temporary package of
+// hgmx css critical. rootSkipDirs are only
+// skipped directly under the root: the Go vendor directory, but not a vendor
+// directory of static scripts.
+var (
+	skipDirs     = []string{"node_modules", "tmp", "dist"}
+	rootSkipDirs = []string{"vendor"}
+)
+
+// Watch sends the path of every file written, created, renamed or removed
+// under root, including directories created later, until ctx is done. Paths
+// for which ignore returns true are dropped.
+func Watch(ctx context.Context, root string, ignore func(path string) bool) (<-chan string, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	root = filepath.Clean(root)
+	if err := addTree(w, root, root); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	events := make(chan string)
+	go func() {
+		defer close(events)
+		defer w.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-w.Errors:
+				// An overflow only loses events, the next change catches up.
+				if !ok {
+					return
+				}
+			case e, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if e.Has(fsnotify.Chmod) && !e.Has(fsnotify.Write) {
+					continue
+				}
+				if e.Has(fsnotify.Create) {
+					if info, err := os.Stat(e.Name); err == nil && info.IsDir() {
+						addTree(w, root, e.Name)
+						continue
+					}
+				}
+				if strings.HasPrefix(filepath.Base(e.Name), ".") || (ignore != nil && ignore(e.Name)) {
+					continue
+				}
+				select {
+				case events <- e.Name:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// addTree watches dir, a directory under root, and every directory below it,
+// unless dir itself is skipped.
+func addTree(w *fsnotify.Watcher, root, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if p != root && skipDir(root, p) {
+			return fs.SkipDir
+		}
+		return w.Add(p)
+	})
+}
+
+// skipDir reports whether the directory p under root is left unwatched.
+func skipDir(root, p string) bool {
+	name := filepath.Base(p)
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || slices.Contains(skipDirs, name) ||
+		filepath.Dir(p) == root && slices.Contains(rootSkipDirs, name)
+}
@@ -0,0 +1,164 @@
+// Package favicon renders the favicons, touch icons and web app manifest
+// icons the layout links from one source image, using only the standard
+// image packages.
+package favicon
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io/fs"
+	"regexp"
+)
+
+// MinSize is the smallest source that is not enlarged for the biggest icon.
+const MinSize = 512
+
+// File is a generated file, named relative to the favicon directory.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// maskable is the share of a maskable icon the image fills, the safe zone
+// launchers never crop.
+const maskable = 0.8
+
+var dataURI = regexp.MustCompile(`data:image/(?:png|jpeg|gif);base64,([A-Za-z0-9+/=\s]+)`)
+
+// Decode reads a PNG, JPEG or GIF source.
+func Decode(b []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(b))
+	return img, err
+}
+
+// Embedded returns the largest raster image embedded as a data URI in an SVG,
+// which is how favicon generators wrap photos and logos. The standard library
+// cannot draw vector SVG, so other SVGs need a raster version alongside.
+func Embedded(svg []byte) (image.Image, error) {
+	var best image.Image
+	for _, m := range dataURI.FindAllSubmatch(svg, -1) {
+		raw, err := base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(m[1]), nil)))
+		if err != nil {
+			continue
+		}
+		img, err := Decode(raw)
+		if err != nil {
+			continue
+		}
+		if best == nil || img.Bounds().Dx()*img.Bounds().Dy() > best.Bounds().Dx()*best.Bounds().Dy() {
+			best = img
+		}
+	}
+	if best == nil {
+		return nil, errors.New("the SVG embeds no raster image to render the PNG icons from")
+	}
+	return best, nil
+}
+
+// Generate renders every icon Favicon links from img, on bg where the icon
+// must be opaque. svg is used as favicon.svg; without one the image is
+// embedded in an SVG so the file still exists.
+func Generate(img image.Image, svg []byte, bg color.Color) ([]File, error) {
+	img = Square(img)
+
+	var files []File
+	add := func(name string, icon image.Image) error {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, icon); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		files = append(files, File{name, buf.Bytes()})
+		return nil
+	}
+	icons := []struct {
+		name string
+		icon image.Image
+	}{
+		{"favicon-96x96.png", Resize(img, 96, 96)},
+		{"apple-touch-icon.png", Compose(img, 180, bg, 1)},
+		{"web-app-manifest-192x192.png", Compose(img, 192, bg, maskable)},
+		{"web-app-manifest-512x512.png", Compose(img, 512, bg, maskable)},
+	}
+	for _, i := range icons {
+		if err := add(i.name, i.icon); err != nil {
+			return nil, err
+		}
+	}
+
+	var ico bytes.Buffer
+	if err := WriteICO(&ico, Resize(img, 16, 16), Resize(img, 32, 32), Resize(img, 48, 48)); err != nil {
+		return nil, err
+	}
+	files = append(files, File{"favicon.ico", ico.Bytes()})
+
+	if svg == nil {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, Resize(img, 192, 192)); err != nil {
+			return nil, err
+		}
+		svg = fmt.Appendf(nil, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 192 192"><image width="192" height="192" href="data:image/png;base64,%s"/></svg>`+"\n",
+			base64.StdEncoding.EncodeToString(buf.Bytes()))
+	}
+	files = append(files, File{"favicon.svg", svg})
+	return files, nil
+}
+
+// ManifestName is the web app manifest kept with the icons.
+const ManifestName = "site.webmanifest"
+
+// Manifest is the web app manifest. Icon sources are relative to it, so they
+// resolve wherever the static directory is served.
+type Manifest struct {
+	Name            string         `json:"name"`
+	ShortName       string         `json:"short_name"`
+	StartURL        string         `json:"start_url,omitempty"`
+	Icons           []ManifestIcon `json:"icons"`
+	ThemeColor      string         `json:"theme_color"`
+	BackgroundColor string         `json:"background_color"`
+	Display         string         `json:"display"`
+}
+
+// ManifestIcon is an entry of Manifest.Icons.
+type ManifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes"`
+	Type    string `json:"type"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// ManifestIcons are the icons Generate renders for the manifest.
+var ManifestIcons = []ManifestIcon{
+	{Src: "web-app-manifest-192x192.png", Sizes: "192x192", Type: "image/png", Purpose: "maskable"},
+	{Src: "web-app-manifest-512x512.png", Sizes: "512x512", Type: "image/png", Purpose: "maskable"},
+}
+
+// LoadManifest reads the manifest name from fsys, keeping the fields hgmx
+// favicon does not set. A missing manifest is a standalone app.
+func LoadManifest(fsys fs.FS, name string) (*Manifest, error) {
+	m := &Manifest{Display: "standalone"}
+	b, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return m, nil
+}
+
+// Marshal encodes the manifest for writing back to disk.
+func (m *Manifest) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(m, "", "  ")
+	return append(b, '\n'), err
+}
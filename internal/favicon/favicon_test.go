@@ -0,0 +1,163 @@
+package favicon
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+	"testing/fstest"
+)
+
+// logo is an opaque red disc in the left half of a transparent 64x32 image.
+func logo() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	for y := range 32 {
+		for x := range 32 {
+			if (x-16)*(x-16)+(y-16)*(y-16) < 14*14 {
+				img.Set(x, y, color.NRGBA{255, 0, 0, 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestResize(t *testing.T) {
+	img := Square(logo())
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Fatalf("Square() = %v, want 64x64", b)
+	}
+
+	small := Resize(img, 16, 16)
+	// the disc sits left of centre after padding, its edge blends but never
+	// darkens towards the transparent background
+	for _, p := range []image.Point{{4, 8}, {2, 8}, {1, 6}} {
+		c := color.NRGBAModel.Convert(small.At(p.X, p.Y)).(color.NRGBA)
+		if c.A == 0 || c.R < 250 || c.G != 0 || c.B != 0 {
+			t.Errorf("Resize() at %v = %v, want red", p, c)
+		}
+	}
+	if c := small.RGBAAt(15, 0); c.A != 0 {
+		t.Errorf("Resize() corner = %v, want transparent", c)
+	}
+
+	big := Resize(img, 128, 128)
+	if c := big.RGBAAt(32, 64); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Resize() up at disc centre = %v", c)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	bg := color.RGBA{0x22, 0x25, 0x36, 0xff}
+	red := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(red, red.Bounds(), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+	img := Compose(red, 100, bg, maskable)
+	if c := img.RGBAAt(5, 5); c != bg {
+		t.Errorf("Compose() outside the safe zone = %v, want %v", c, bg)
+	}
+	if c := img.RGBAAt(50, 50); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Compose() centre = %v", c)
+	}
+}
+
+func TestWriteICO(t *testing.T) {
+	var buf bytes.Buffer
+	sizes := []int{16, 32, 256}
+	var imgs []image.Image
+	for _, s := range sizes {
+		imgs = append(imgs, image.NewRGBA(image.Rect(0, 0, s, s)))
+	}
+	if err := WriteICO(&buf, imgs...); err != nil {
+		t.Fatal(err)
+	}
+
+	b := buf.Bytes()
+	le := binary.LittleEndian
+	if le.Uint16(b[2:]) != 1 || int(le.Uint16(b[4:])) != len(sizes) {
+		t.Fatalf("ICONDIR = % x", b[:6])
+	}
+	for i, s := range sizes {
+		e := b[6+16*i:]
+		if want := byte(s % 256); e[0] != want || e[1] != want {
+			t.Errorf("entry %d is %dx%d, want %d", i, e[0], e[1], want)
+		}
+		size, offset := le.Uint32(e[8:]), le.Uint32(e[12:])
+		img, err := png.Decode(bytes.NewReader(b[offset : offset+size]))
+		if err != nil || img.Bounds().Dx() != s {
+			t.Errorf("entry %d PNG = %v, %v", i, img.Bounds(), err)
+		}
+	}
+
+	if err := WriteICO(&buf, image.NewRGBA(image.Rect(0, 0, 512, 512))); err == nil {
+		t.Error("WriteICO() accepted a 512px image")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	files, err := Generate(logo(), nil, color.Black)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{
+		"favicon-96x96.png":            96,
+		"apple-touch-icon.png":         180,
+		"web-app-manifest-192x192.png": 192,
+		"web-app-manifest-512x512.png": 512,
+	}
+	names := make(map[string][]byte)
+	for _, f := range files {
+		names[f.Name] = f.Data
+	}
+	for name, size := range want {
+		img, err := png.Decode(bytes.NewReader(names[name]))
+		if err != nil || img.Bounds().Dx() != size || img.Bounds().Dy() != size {
+			t.Errorf("%s = %v, %v, want %dpx", name, img, err, size)
+		}
+	}
+	if _, ok := names["favicon.ico"]; !ok {
+		t.Error("Generate() wrote no favicon.ico")
+	}
+
+	// the SVG written for a raster source embeds it, and reads back
+	img, err := Embedded(names["favicon.svg"])
+	if err != nil || img.Bounds().Dx() != 192 {
+		t.Errorf("Embedded(favicon.svg) = %v, %v", img, err)
+	}
+	if _, err := Embedded([]byte(`<svg><path d="M0 0"/></svg>`)); err == nil {
+		t.Error("Embedded() found a raster in a vector SVG")
+	}
+}
+
+func TestEmbeddedLargest(t *testing.T) {
+	uri := func(s int) string {
+		var buf bytes.Buffer
+		png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, s, s)))
+		return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	}
+	svg := `<svg><image href="` + uri(8) + `"/><image xlink:href="` + uri(40) + `"/></svg>`
+	img, err := Embedded([]byte(svg))
+	if err != nil || img.Bounds().Dx() != 40 {
+		t.Errorf("Embedded() = %v, %v, want the 40px image", img, err)
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		ManifestName: {Data: []byte(`{"name":"","start_url":"/app","display":"fullscreen"}`)},
+	}
+	m, err := LoadManifest(fsys, ManifestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.StartURL != "/app" || m.Display != "fullscreen" {
+		t.Errorf("LoadManifest() = %+v, want existing fields kept", m)
+	}
+
+	m, err = LoadManifest(fstest.MapFS{}, ManifestName)
+	if err != nil || m.Display != "standalone" {
+		t.Errorf("LoadManifest() without a file = %+v, %v", m, err)
+	}
+}
@@ -0,0 +1,53 @@
+package favicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// WriteICO writes imgs as one .ico file. Each entry holds a PNG, which every
+// browser and Windows since Vista read.
+func WriteICO(w io.Writer, imgs ...image.Image) error {
+	if len(imgs) == 0 {
+		return fmt.Errorf("ico: no images")
+	}
+	data := make([][]byte, len(imgs))
+	for i, img := range imgs {
+		if b := img.Bounds(); b.Dx() > 256 || b.Dy() > 256 {
+			return fmt.Errorf("ico: %dx%d is larger than 256x256", b.Dx(), b.Dy())
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		data[i] = buf.Bytes()
+	}
+
+	// ICONDIR, then a 16 byte ICONDIRENTRY per image, then the images
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.Write(le.AppendUint16(nil, 0))
+	buf.Write(le.AppendUint16(nil, 1))
+	buf.Write(le.AppendUint16(nil, uint16(len(imgs))))
+	offset := 6 + 16*len(imgs)
+	for i, img := range imgs {
+		b := img.Bounds()
+		buf.WriteByte(byte(b.Dx())) // 256 wraps to 0, as the format wants
+		buf.WriteByte(byte(b.Dy()))
+		buf.Write([]byte{0, 0}) // palette size, reserved
+		buf.Write(le.AppendUint16(nil, 1))
+		buf.Write(le.AppendUint16(nil, 32))
+		buf.Write(le.AppendUint32(nil, uint32(len(data[i]))))
+		buf.Write(le.AppendUint32(nil, uint32(offset)))
+		offset += len(data[i])
+	}
+	for _, d := range data {
+		buf.Write(d)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
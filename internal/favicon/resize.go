@@ -0,0 +1,122 @@
+package favicon
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// contrib is the weight of source pixel i in a destination pixel.
+type contrib struct {
+	i int
+	w float64
+}
+
+// weights maps each of dst pixels to the src pixels it samples. Shrinking
+// averages the area a pixel covers, which keeps thin lines from dropping out;
+// enlarging interpolates linearly.
+func weights(src, dst int) [][]contrib {
+	out := make([][]contrib, dst)
+	scale := float64(src) / float64(dst)
+	for d := range out {
+		var cs []contrib
+		if scale >= 1 {
+			lo, hi := float64(d)*scale, float64(d+1)*scale
+			for i := int(lo); i < src && float64(i) < hi; i++ {
+				if w := math.Min(hi, float64(i+1)) - math.Max(lo, float64(i)); w > 0 {
+					cs = append(cs, contrib{i, w})
+				}
+			}
+		} else {
+			c := (float64(d)+0.5)*scale - 0.5
+			i := math.Floor(c)
+			f := c - i
+			cs = append(cs, contrib{clamp(int(i), src), 1 - f}, contrib{clamp(int(i)+1, src), f})
+		}
+		sum := 0.0
+		for _, c := range cs {
+			sum += c.w
+		}
+		for j := range cs {
+			cs[j].w /= sum
+		}
+		out[d] = cs
+	}
+	return out
+}
+
+func clamp(i, n int) int {
+	return max(0, min(i, n-1))
+}
+
+// Resize scales img to w×h. It works on premultiplied colors so transparent
+// pixels do not darken the edges they are averaged with.
+func Resize(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	sw, sh := b.Dx(), b.Dy()
+
+	// horizontal pass into floats, then vertical into the result
+	xs, ys := weights(sw, w), weights(sh, h)
+	tmp := make([]float64, w*sh*4)
+	for y := range sh {
+		row := src.Pix[y*src.Stride:]
+		for x, cs := range xs {
+			o := (y*w + x) * 4
+			for _, c := range cs {
+				p := row[c.i*4:]
+				for k := range 4 {
+					tmp[o+k] += float64(p[k]) * c.w
+				}
+			}
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y, cs := range ys {
+		for x := range w {
+			var px [4]float64
+			for _, c := range cs {
+				o := (c.i*w + x) * 4
+				for k := range 4 {
+					px[k] += tmp[o+k] * c.w
+				}
+			}
+			o := y*dst.Stride + x*4
+			a := math.Round(px[3])
+			for k := range 4 {
+				// premultiplied channels never exceed alpha
+				dst.Pix[o+k] = uint8(math.Min(math.Round(px[k]), a))
+			}
+		}
+	}
+	return dst
+}
+
+// Square pads img with transparency to a centred square, so icons keep their
+// aspect ratio.
+func Square(img image.Image) image.Image {
+	b := img.Bounds()
+	if b.Dx() == b.Dy() {
+		return img
+	}
+	side := max(b.Dx(), b.Dy())
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	at := image.Pt((side-b.Dx())/2, (side-b.Dy())/2)
+	draw.Draw(dst, b.Sub(b.Min).Add(at), img, b.Min, draw.Src)
+	return dst
+}
+
+// Compose draws img at scale of size, centred on an opaque background. Home
+// screens and launchers do not show transparency, and maskable icons must keep
+// their content inside the centre 80%.
+func Compose(img image.Image, size int, bg color.Color, scale float64) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	inner := int(math.Round(float64(size) * scale))
+	at := (size - inner) / 2
+	draw.Draw(dst, image.Rect(at, at, at+inner, at+inner), Resize(img, inner, inner), image.Point{}, draw.Over)
+	return dst
+}
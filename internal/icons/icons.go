@@ -0,0 +1,309 @@
+// Package icons optimises SVG icons into symbols of a single sprite sheet and
+// generates the Go constants naming them.
+package icons
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Symbol is an optimised icon.
+type Symbol struct {
+	Name    string
+	ViewBox string
+	Attrs   []xml.Attr // presentation attributes of the root, e.g. stroke-width
+	Body    string     // optimised children of the root
+}
+
+// dropElements never render.
+var dropElements = []string{"metadata", "title", "desc", "script", "foreignObject"}
+
+// inherited are root attributes kept on the symbol, which its children inherit.
+var inherited = []string{
+	"fill", "fill-rule", "clip-rule", "stroke", "stroke-width", "stroke-linecap",
+	"stroke-linejoin", "stroke-miterlimit", "stroke-dasharray", "opacity",
+}
+
+// colorAttrs are normalised to currentColor.
+var colorAttrs = []string{"fill", "stroke", "color", "stop-color", "flood-color", "lighting-color"}
+
+var (
+	styleColor = regexp.MustCompile(`((?:^|[;{\s])(?:fill|stroke|color|stop-color|flood-color|lighting-color)\s*:\s*)([^;}]+)`)
+	urlRef     = regexp.MustCompile(`url\(\s*['"]?#([^'")\s]+)['"]?\s*\)`)
+	nameChars  = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Name returns the icon name for an SVG file: "Arrow Left.svg" becomes
+// "arrow-left".
+func Name(file string) string {
+	base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	return strings.Trim(nameChars.ReplaceAllString(strings.ToLower(base), "-"), "-")
+}
+
+// Names returns the icon name of each file. It fails when a name is empty, as
+// for "+.svg", or when two files share a name, as "arrow_left.svg" and
+// "Arrow Left.svg" do.
+func Names(files []string) ([]string, error) {
+	names := make([]string, len(files))
+	seen := make(map[string]string)
+	for i, file := range files {
+		name := Name(file)
+		if name == "" {
+			return nil, fmt.Errorf("%s: no letters or digits to name the icon after", file)
+		}
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%s and %s are both named %s", other, file, name)
+		}
+		seen[name] = file
+		names[i] = name
+	}
+	return names, nil
+}
+
+// ConstName returns the Go constant for an icon name: "arrow-left" becomes
+// "IconArrowLeft".
+func ConstName(name string) string {
+	var sb strings.Builder
+	sb.WriteString("Icon")
+	for _, part := range strings.Split(name, "-") {
+		if part != "" {
+			sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return sb.String()
+}
+
+// Optimize parses svg and strips what an inline icon does not need: comments,
+// metadata, editor namespaces and root sizing. Colors other than none and
+// gradients become currentColor so icons follow the text color, and ids are
+// prefixed with name so symbols in one sprite cannot collide.
+func Optimize(name string, svg []byte) (*Symbol, error) {
+	d := xml.NewDecoder(bytes.NewReader(svg))
+	d.Strict = false
+
+	s := &Symbol{Name: name}
+	var out []xml.Token
+	depth, skip := 0, 0
+	for {
+		tok, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if skip > 0 || t.Name.Space != "" || slices.Contains(dropElements, t.Name.Local) {
+				skip++
+				continue
+			}
+			if depth == 1 {
+				if t.Name.Local != "svg" {
+					return nil, fmt.Errorf("%s: root element is <%s>, not <svg>", name, t.Name.Local)
+				}
+				if err := s.root(t.Attr); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			t.Attr = cleanAttrs(name, t.Attr)
+			out = append(out, t.Copy())
+		case xml.EndElement:
+			depth--
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if depth > 0 {
+				out = append(out, t)
+			}
+		case xml.CharData:
+			if skip > 0 || depth < 1 {
+				continue
+			}
+			text := strings.TrimSpace(string(t))
+			if text == "" {
+				continue
+			}
+			if n := len(out); n > 0 {
+				if start, ok := out[n-1].(xml.StartElement); ok && start.Name.Local == "style" {
+					text = prefixRefs(name, normaliseStyle(text))
+				}
+			}
+			out = append(out, xml.CharData(text))
+		}
+	}
+	if s.ViewBox == "" {
+		return nil, fmt.Errorf("%s: not an SVG document", name)
+	}
+	dropUnusedIDs(out)
+	s.Body = serialize(out)
+	return s, nil
+}
+
+// dropUnusedIDs removes the ids no href or url() refers to, such as the layer
+// names editors add.
+func dropUnusedIDs(tokens []xml.Token) {
+	used := make(map[string]bool)
+	for _, tok := range tokens {
+		var values []string
+		switch t := tok.(type) {
+		case xml.StartElement:
+			for _, a := range t.Attr {
+				values = append(values, a.Value)
+				if a.Name.Local == "href" && strings.HasPrefix(a.Value, "#") {
+					used[a.Value[1:]] = true
+				}
+			}
+		case xml.CharData:
+			values = append(values, string(t))
+		}
+		for _, v := range values {
+			for _, m := range urlRef.FindAllStringSubmatch(v, -1) {
+				used[m[1]] = true
+			}
+		}
+	}
+	for i, tok := range tokens {
+		if t, ok := tok.(xml.StartElement); ok {
+			t.Attr = slices.DeleteFunc(t.Attr, func(a xml.Attr) bool { return a.Name.Local == "id" && !used[a.Value] })
+			tokens[i] = t
+		}
+	}
+}
+
+// root reads the viewBox and inherited attributes of the <svg> element.
+func (s *Symbol) root(attrs []xml.Attr) error {
+	var width, height string
+	for _, a := range attrs {
+		switch {
+		case a.Name.Space != "":
+		case a.Name.Local == "viewBox":
+			s.ViewBox = strings.Join(strings.Fields(strings.ReplaceAll(a.Value, ",", " ")), " ")
+		case a.Name.Local == "width":
+			width = strings.TrimSuffix(a.Value, "px")
+		case a.Name.Local == "height":
+			height = strings.TrimSuffix(a.Value, "px")
+		case slices.Contains(inherited, a.Name.Local):
+			if slices.Contains(colorAttrs, a.Name.Local) {
+				a.Value = normaliseColor(a.Value)
+			}
+			s.Attrs = append(s.Attrs, xml.Attr{Name: xml.Name{Local: a.Name.Local}, Value: a.Value})
+		}
+	}
+	if s.ViewBox == "" && width != "" && height != "" && !strings.HasSuffix(width, "%") {
+		s.ViewBox = "0 0 " + width + " " + height
+	}
+	if s.ViewBox == "" {
+		return fmt.Errorf("%s: <svg> has neither a viewBox nor a width and height", s.Name)
+	}
+	return nil
+}
+
+// cleanAttrs drops namespaced and editor attributes, normalises colors and
+// prefixes ids and references with name.
+func cleanAttrs(name string, attrs []xml.Attr) []xml.Attr {
+	out := attrs[:0:0]
+	for _, a := range attrs {
+		switch {
+		case a.Name.Space == "xlink" && a.Name.Local == "href":
+			a.Name = xml.Name{Local: "href"}
+		case a.Name.Space != "" || a.Name.Local == "xmlns" || strings.HasPrefix(a.Name.Local, "data-"):
+			continue
+		}
+		switch {
+		case a.Name.Local == "id":
+			a.Value = prefixID(name, a.Value)
+		case a.Name.Local == "href" && strings.HasPrefix(a.Value, "#"):
+			a.Value = "#" + prefixID(name, a.Value[1:])
+		case a.Name.Local == "style":
+			a.Value = normaliseStyle(a.Value)
+		case slices.Contains(colorAttrs, a.Name.Local):
+			a.Value = normaliseColor(a.Value)
+		}
+		a.Value = prefixRefs(name, a.Value)
+		out = append(out, a)
+	}
+	return out
+}
+
+func prefixID(name, id string) string {
+	if strings.HasPrefix(id, name+"-") {
+		return id
+	}
+	return name + "-" + id
+}
+
+func prefixRefs(name, value string) string {
+	return urlRef.ReplaceAllStringFunc(value, func(ref string) string {
+		return "url(#" + prefixID(name, urlRef.FindStringSubmatch(ref)[1]) + ")"
+	})
+}
+
+// normaliseColor maps a paint to currentColor, keeping none and references.
+func normaliseColor(v string) string {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "none", "transparent", "inherit", "currentcolor":
+		return strings.TrimSpace(v)
+	}
+	if strings.HasPrefix(strings.TrimSpace(v), "url(") {
+		return v
+	}
+	return "currentColor"
+}
+
+// normaliseStyle normalises the color declarations of a style attribute or
+// element.
+func normaliseStyle(css string) string {
+	return styleColor.ReplaceAllStringFunc(css, func(decl string) string {
+		m := styleColor.FindStringSubmatch(decl)
+		return m[1] + normaliseColor(m[2])
+	})
+}
+
+var attrEscaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;")
+
+// serialize writes tokens back as markup, closing empty elements with />.
+func serialize(tokens []xml.Token) string {
+	var sb strings.Builder
+	for i, tok := range tokens {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			sb.WriteString("<" + t.Name.Local)
+			writeAttrs(&sb, t.Attr)
+			if i+1 < len(tokens) {
+				if _, ok := tokens[i+1].(xml.EndElement); ok {
+					sb.WriteString("/>")
+					continue
+				}
+			}
+			sb.WriteString(">")
+		case xml.EndElement:
+			if i > 0 {
+				if _, ok := tokens[i-1].(xml.StartElement); ok {
+					continue
+				}
+			}
+			sb.WriteString("</" + t.Name.Local + ">")
+		case xml.CharData:
+			xml.EscapeText(&sb, t)
+		}
+	}
+	return sb.String()
+}
+
+func writeAttrs(sb *strings.Builder, attrs []xml.Attr) {
+	for _, a := range attrs {
+		sb.WriteString(" " + a.Name.Local + `="` + attrEscaper.Replace(a.Value) + `"`)
+	}
+}
@@ -0,0 +1,119 @@
+package icons
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const editorSVG = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- exported -->
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape" width="16px" height="16px" version="1.1" stroke="#333" stroke-width="1.5" inkscape:version="1.3">
+  <title>Logo</title>
+  <metadata><rdf/></metadata>
+  <inkscape:grid id="grid"/>
+  <defs>
+    <linearGradient id="fade"><stop offset="0" stop-color="#fff"/></linearGradient>
+  </defs>
+  <g id="layer1" inkscape:label="Layer" data-name="x">
+    <path d="M0 0h16" fill="url(#fade)" style="stroke: rgb(0, 0, 0); opacity: .5"/>
+    <use xlink:href="#shape" fill="none"/>
+    <circle id="shape" cx="8" cy="8" r="4" fill="#F00"/>
+  </g>
+</svg>`
+
+func TestOptimize(t *testing.T) {
+	s, err := Optimize("logo", []byte(editorSVG))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ViewBox != "0 0 16 16" {
+		t.Errorf("ViewBox = %q, want it from width and height", s.ViewBox)
+	}
+	var attrs []string
+	for _, a := range s.Attrs {
+		attrs = append(attrs, a.Name.Local+"="+a.Value)
+	}
+	if got := strings.Join(attrs, " "); got != "stroke=currentColor stroke-width=1.5" {
+		t.Errorf("Attrs = %s", got)
+	}
+	want := `<defs><linearGradient id="logo-fade"><stop offset="0" stop-color="currentColor"/></linearGradient></defs>` +
+		`<g><path d="M0 0h16" fill="url(#logo-fade)" style="stroke: currentColor; opacity: .5"/>` +
+		`<use href="#logo-shape" fill="none"/><circle id="logo-shape" cx="8" cy="8" r="4" fill="currentColor"/></g>`
+	if s.Body != want {
+		t.Errorf("Body =\n%s\nwant\n%s", s.Body, want)
+	}
+
+	again, err := Optimize("logo", []byte(s.SVG()))
+	if err != nil || again.Body != s.Body || again.ViewBox != s.ViewBox || len(again.Attrs) != len(s.Attrs) {
+		t.Errorf("Optimize(SVG()) = %+v, %v, want it unchanged", again, err)
+	}
+
+	for _, bad := range []string{`<html></html>`, `<svg xmlns="http://www.w3.org/2000/svg"><path/></svg>`, `not xml`} {
+		if _, err := Optimize("bad", []byte(bad)); err == nil {
+			t.Errorf("Optimize(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	for file, want := range map[string][2]string{
+		"icons/Arrow Left.svg": {"arrow-left", "IconArrowLeft"},
+		"x.svg":                {"x", "IconX"},
+		"2fa_lock--alt.svg":    {"2fa-lock-alt", "Icon2faLockAlt"},
+	} {
+		if name := Name(file); name != want[0] || ConstName(name) != want[1] {
+			t.Errorf("Name(%q) = %q, %q, want %q", file, name, ConstName(name), want)
+		}
+	}
+}
+
+func TestNamesConflicts(t *testing.T) {
+	names, err := Names([]string{"a/arrow-left.svg", "b/x.svg"})
+	if err != nil || strings.Join(names, ",") != "arrow-left,x" {
+		t.Errorf("Names() = %q, %v", names, err)
+	}
+	for _, files := range [][]string{
+		{"arrow_left.svg", "Arrow Left.svg"},
+		{"x.svg", "+.svg"},
+		{"___.svg"},
+	} {
+		if _, err := Names(files); err == nil {
+			t.Errorf("Names(%q) succeeded", files)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	symbols := []*Symbol{
+		{Name: "arrow-left", ViewBox: "0 0 24 24", Body: `<path d="M19 12H5"/>`},
+		{Name: "x", ViewBox: "0 0 24 24", Body: `<path d="M18 6 6 18"/>`},
+	}
+
+	var sprite strings.Builder
+	if err := WriteSprite(&sprite, symbols); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sprite.String(), `<symbol id="arrow-left" viewBox="0 0 24 24"><path d="M19 12H5"/></symbol>`) {
+		t.Errorf("sprite = %s", sprite.String())
+	}
+
+	var src strings.Builder
+	if err := WriteGo(&src, "display", symbols); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "icons.go", src.String(), 0); err != nil {
+		t.Fatalf("generated Go does not parse: %v\n%s", err, src.String())
+	}
+	for _, want := range []string{"package display", "IconArrowLeft IconName = iota", `IconX:         "x",`} {
+		if !strings.Contains(src.String(), want) {
+			t.Errorf("generated Go lacks %q:\n%s", want, src.String())
+		}
+	}
+
+	src.Reset()
+	if err := WriteGo(&src, "display", nil); err != nil || strings.Contains(src.String(), "const (") {
+		t.Errorf("WriteGo() without icons = %q, %v", src.String(), err)
+	}
+}
@@ -0,0 +1,65 @@
+package icons
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+)
+
+// SVG returns s as a standalone SVG document, the form icons are kept in
+// before they are combined. Optimize reads it back unchanged.
+func (s *Symbol) SVG() string {
+	var sb strings.Builder
+	sb.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="` + attrEscaper.Replace(s.ViewBox) + `"`)
+	writeAttrs(&sb, s.Attrs)
+	sb.WriteString(">" + s.Body + "</svg>\n")
+	return sb.String()
+}
+
+// WriteSprite writes symbols as one SVG sprite, each referenced by its name:
+// <use href="sprite.svg#arrow-left"/>.
+func WriteSprite(w io.Writer, symbols []*Symbol) error {
+	var sb strings.Builder
+	sb.WriteString(`<svg xmlns="http://www.w3.org/2000/svg">` + "\n")
+	for _, s := range symbols {
+		sb.WriteString(`<symbol id="` + attrEscaper.Replace(s.Name) + `" viewBox="` + attrEscaper.Replace(s.ViewBox) + `"`)
+		writeAttrs(&sb, s.Attrs)
+		sb.WriteString(">" + s.Body + "</symbol>\n")
+	}
+	sb.WriteString("</svg>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteGo writes the Go source declaring an IconName constant per symbol, in
+// package pkg, and the ids Icon looks them up by. IconName itself is declared
+// next to the Icon component.
+func WriteGo(w io.Writer, pkg string, symbols []*Symbol) error {
+	var sb strings.Builder
+	sb.WriteString("// Code generated by hgmx icons; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&sb, "package %s\n\n", pkg)
+	if len(symbols) > 0 {
+		sb.WriteString("// Icons in the sprite, for Icon.\nconst (\n")
+		for i, s := range symbols {
+			if i == 0 {
+				fmt.Fprintf(&sb, "%s IconName = iota\n", ConstName(s.Name))
+			} else {
+				fmt.Fprintf(&sb, "%s\n", ConstName(s.Name))
+			}
+		}
+		sb.WriteString(")\n\n")
+	}
+	sb.WriteString("// iconIDs are the sprite symbol ids of the IconName constants.\nvar iconIDs = [...]string{\n")
+	for _, s := range symbols {
+		fmt.Fprintf(&sb, "%s: %q,\n", ConstName(s.Name), s.Name)
+	}
+	sb.WriteString("}\n")
+
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
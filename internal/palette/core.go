@@ -1,10 +1,13 @@
 package palette
 
 import (
+	"cmp"
 	"fmt"
 	"image/color"
 	"math"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/alltom/oklab"
 )
@@ -19,8 +22,8 @@ func HexToOklch(hexColor string) (oklchColor oklab.Oklch, err error) {
 	g, errG := strconv.ParseUint(hexColor[3:5], 16, 8)
 	b, errB := strconv.ParseUint(hexColor[5:7], 16, 8)
 
-	if errR != nil || errG != nil || errB != nil {
-		return
+	if err := cmp.Or(errR, errG, errB); err != nil {
+		return oklchColor, fmt.Errorf("invalid hex color digits in %s: %w", hexColor, err)
 	}
 
 	rgbaColor := color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
@@ -34,6 +37,11 @@ func OklchToString(oklchColor *oklab.Oklch) string {
 	return fmt.Sprintf("oklch(%.2f %.3f %06.2f)", oklchColor.L, oklchColor.C, toDegree(oklchColor.H))
 }
 
+// OklchAlphaToString converts an OKLCH color and an alpha (0.0-1.0) to a css string.
+func OklchAlphaToString(oklchColor *oklab.Oklch, alpha float64) string {
+	return fmt.Sprintf("oklch(%.2f %.3f %06.2f / %.2f)", oklchColor.L, oklchColor.C, toDegree(oklchColor.H), max(0, min(alpha, 1)))
+}
+
 func OklchToHex(oklchColor *oklab.Oklch) string {
 	if oklchColor == nil {
 		return "#000000"
@@ -47,6 +55,33 @@ func OklchToHex(oklchColor *oklab.Oklch) string {
 	return fmt.Sprintf("#%02x%02x%02x", r8, g8, b8)
 }
 
+var cssOklch = regexp.MustCompile(`^oklch\(\s*([\d.]+)\s+([\d.]+)\s+([\d.]+)\s*\)$`)
+
+// ParseOklch parses a css string written by OklchToString, e.g.
+// "oklch(0.27 0.032 276.31)".
+func ParseOklch(css string) (oklchColor oklab.Oklch, err error) {
+	m := cssOklch.FindStringSubmatch(strings.TrimSpace(css))
+	if m == nil {
+		return oklchColor, fmt.Errorf("invalid oklch color: %s", css)
+	}
+	oklchColor.L, _ = strconv.ParseFloat(m[1], 64)
+	oklchColor.C, _ = strconv.ParseFloat(m[2], 64)
+	hue, _ := strconv.ParseFloat(m[3], 64)
+	oklchColor.H = hue * math.Pi / 180
+	return
+}
+
+// ShadeFromCSS finds the --<color>-<shade> variable in a stylesheet written by
+// ToCSS, such as colors.css.
+func ShadeFromCSS(css string, c Color, shade int) (oklchColor oklab.Oklch, err error) {
+	decl := regexp.MustCompile(`--` + regexp.QuoteMeta(string(c)) + `-` + strconv.Itoa(shade) + `\s*:\s*([^;]+);`)
+	m := decl.FindStringSubmatch(css)
+	if m == nil {
+		return oklchColor, fmt.Errorf("--%s-%d is not defined", c, shade)
+	}
+	return ParseOklch(m[1])
+}
+
 func toDegree(hue float64) float64 {
 	hueDegrees := hue * 180 / math.Pi
 	if hueDegrees < 0 {
@@ -96,3 +131,25 @@ func OklchCompare(c1, c2 oklab.Oklch) (rl float64, cr float64) {
 	cr = ContrastRatio(c1, c2)
 	return
 }
+
+// Composite blends a translucent color over an opaque background (alpha 0.0-1.0),
+// the same way browsers do, in gamma-encoded sRGB.
+func Composite(c oklab.Oklch, alpha float64, bg oklab.Oklch) oklab.Oklch {
+	alpha = max(0, min(alpha, 1))
+	r1, g1, b1 := c.Oklab().SRGB()
+	r2, g2, b2 := bg.Oklab().SRGB()
+
+	blend := func(fg, bg float64) uint8 {
+		v := max(0, min(fg, 1))*alpha + max(0, min(bg, 1))*(1-alpha)
+		return uint8(math.Round(v * 255))
+	}
+
+	rgbaColor := color.RGBA{R: blend(r1, r2), G: blend(g1, g2), B: blend(b1, b2), A: 255}
+	return oklab.OklchModel.Convert(rgbaColor).(oklab.Oklch)
+}
+
+// ContrastRatioAlpha calculates the contrast ratio of a translucent color (c1 at alpha)
+// composited over an opaque background (c2) against that background.
+func ContrastRatioAlpha(c1 oklab.Oklch, alpha float64, c2 oklab.Oklch) float64 {
+	return ContrastRatio(Composite(c1, alpha, c2), c2)
+}
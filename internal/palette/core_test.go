@@ -0,0 +1,154 @@
+package palette
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/alltom/oklab"
+)
+
+func TestHexRoundTrip(t *testing.T) {
+	// a prime stride samples every channel value without walking all 16M colors
+	const stride = 257
+	for v := 0; v < 1<<24; v += stride {
+		hex := fmt.Sprintf("#%06x", v)
+		c, err := HexToOklch(hex)
+		if err != nil {
+			t.Fatalf("HexToOklch(%q): %v", hex, err)
+		}
+		if got := OklchToHex(&c); got != hex {
+			t.Fatalf("round trip %s -> %+v -> %s", hex, c, got)
+		}
+	}
+
+	for _, hex := range []string{"#000000", "#ffffff", "#ff0000", "#00ff00", "#0000ff"} {
+		c, err := HexToOklch(hex)
+		if err != nil {
+			t.Fatalf("HexToOklch(%q): %v", hex, err)
+		}
+		if got := OklchToHex(&c); got != hex {
+			t.Errorf("round trip %s -> %s", hex, got)
+		}
+	}
+}
+
+func TestHexToOklchInvalid(t *testing.T) {
+	for _, hex := range []string{"", "#", "222536", "#22253", "#2225366", "#zzzzzz", "#-12345", "#+12345", " 222536"} {
+		if _, err := HexToOklch(hex); err == nil {
+			t.Errorf("HexToOklch(%q) expected error", hex)
+		}
+	}
+}
+
+func TestShadeFromCSS(t *testing.T) {
+	var css strings.Builder
+	p := Generate("#222536")
+	p.ToCSS(&css)
+
+	want := p[Base].Shades[600].Oklch
+	got, err := ShadeFromCSS(css.String(), Base, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if OklchToString(&got) != OklchToString(&want) {
+		t.Errorf("ShadeFromCSS() = %s, want %s", OklchToString(&got), OklchToString(&want))
+	}
+
+	if _, err := ShadeFromCSS(css.String(), Base, 650); err == nil {
+		t.Error("ShadeFromCSS() found an undefined shade")
+	}
+	if _, err := ParseOklch("oklch(0.5 0.1)"); err == nil {
+		t.Error("ParseOklch() accepted two components")
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	// reference values from the WCAG 2.x relative luminance definition
+	tests := []struct {
+		fg, bg string
+		want   float64
+	}{
+		{"#000000", "#ffffff", 21.0},
+		{"#ffffff", "#ffffff", 1.0},
+		{"#777777", "#ffffff", 4.48},
+		{"#767676", "#ffffff", 4.54},
+		{"#595959", "#ffffff", 7.0},
+		{"#0000ff", "#ffffff", 8.59},
+		{"#ff0000", "#ffffff", 4.0},
+		{"#008000", "#ffffff", 5.14},
+		{"#ffff00", "#000000", 19.56},
+	}
+	for _, tt := range tests {
+		fg, _ := HexToOklch(tt.fg)
+		bg, _ := HexToOklch(tt.bg)
+		got := ContrastRatio(fg, bg)
+		if math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("ContrastRatio(%s, %s) = %.3f, want %.2f", tt.fg, tt.bg, got, tt.want)
+		}
+		if swapped := ContrastRatio(bg, fg); swapped != got {
+			t.Errorf("ContrastRatio(%s, %s) not symmetric: %.3f != %.3f", tt.fg, tt.bg, got, swapped)
+		}
+	}
+}
+
+func TestContrastRatioAlpha(t *testing.T) {
+	fg, _ := HexToOklch("#000000")
+	bg, _ := HexToOklch("#ffffff")
+
+	if got := ContrastRatioAlpha(fg, 1, bg); math.Abs(got-21) > 0.01 {
+		t.Errorf("opaque contrast = %.3f, want 21", got)
+	}
+	if got := ContrastRatioAlpha(fg, 0, bg); math.Abs(got-1) > 0.01 {
+		t.Errorf("transparent contrast = %.3f, want 1", got)
+	}
+
+	// 50% black over white is mid gray in gamma-encoded sRGB
+	gray, _ := HexToOklch("#808080")
+	if got := Composite(fg, 0.5, bg); DeltaEOK(got, gray) > 0.005 {
+		t.Errorf("Composite 50%% black over white = %s, want #808080", OklchToHex(&got))
+	}
+
+	prev := math.Inf(1)
+	for alpha := 1.0; alpha >= 0; alpha -= 0.1 {
+		got := ContrastRatioAlpha(fg, alpha, bg)
+		if got > prev+1e-9 {
+			t.Errorf("contrast increased as alpha decreased to %.1f: %.3f > %.3f", alpha, got, prev)
+		}
+		prev = got
+	}
+}
+
+func TestDeltaEOK(t *testing.T) {
+	black := oklab.Oklch{L: 0}
+	white := oklab.Oklch{L: 1}
+	if got := DeltaEOK(black, white); math.Abs(got-1) > 1e-9 {
+		t.Errorf("DeltaEOK(black, white) = %f, want 1", got)
+	}
+	c, _ := HexToOklch("#222536")
+	if got := DeltaEOK(c, c); got != 0 {
+		t.Errorf("DeltaEOK(c, c) = %f, want 0", got)
+	}
+}
+
+func FuzzHexToOklch(f *testing.F) {
+	for _, seed := range []string{"#222536", "#000000", "#FFFFFF", "#zzzzzz", "222536", "#12345", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, hex string) {
+		c, err := HexToOklch(hex)
+		if err != nil {
+			return
+		}
+		if len(hex) != 7 || hex[0] != '#' {
+			t.Fatalf("HexToOklch(%q) accepted malformed input", hex)
+		}
+		if got := OklchToHex(&c); got != strings.ToLower(hex) {
+			t.Fatalf("round trip %q -> %q", hex, got)
+		}
+		if c.L < 0 || c.L > 1 || c.C < 0 || math.IsNaN(c.H) {
+			t.Fatalf("HexToOklch(%q) out of range: %+v", hex, c)
+		}
+	})
+}
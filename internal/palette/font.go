@@ -0,0 +1,85 @@
+package palette
+
+import (
+	"image"
+	"image/color"
+)
+
+// A tiny 5x7 bitmap font, just enough glyphs to label swatches with color
+// names, hex codes and OKLCH values without pulling in a font renderer.
+
+const (
+	glyphWidth   = 5
+	glyphHeight  = 7
+	glyphAdvance = glyphWidth + 1
+)
+
+var glyphs = map[rune][glyphHeight]string{
+	'a': {".....", ".....", ".###.", "....#", ".####", "#...#", ".####"},
+	'b': {"#....", "#....", "####.", "#...#", "#...#", "#...#", "####."},
+	'c': {".....", ".....", ".####", "#....", "#....", "#....", ".####"},
+	'd': {"....#", "....#", ".####", "#...#", "#...#", "#...#", ".####"},
+	'e': {".....", ".....", ".###.", "#...#", "#####", "#....", ".###."},
+	'f': {"..##.", ".#...", "####.", ".#...", ".#...", ".#...", ".#..."},
+	'g': {".....", ".####", "#...#", "#...#", ".####", "....#", ".###."},
+	'h': {"#....", "#....", "####.", "#...#", "#...#", "#...#", "#...#"},
+	'i': {"..#..", ".....", ".##..", "..#..", "..#..", "..#..", ".###."},
+	'j': {"...#.", ".....", "..##.", "...#.", "...#.", "#..#.", ".##.."},
+	'k': {"#....", "#....", "#..#.", "#.#..", "##...", "#.#..", "#..#."},
+	'l': {".##..", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'm': {".....", ".....", "##.#.", "#.#.#", "#.#.#", "#.#.#", "#.#.#"},
+	'n': {".....", ".....", "####.", "#...#", "#...#", "#...#", "#...#"},
+	'o': {".....", ".....", ".###.", "#...#", "#...#", "#...#", ".###."},
+	'p': {".....", "####.", "#...#", "#...#", "####.", "#....", "#...."},
+	'q': {".....", ".####", "#...#", "#...#", ".####", "....#", "....#"},
+	'r': {".....", ".....", "#.##.", "##..#", "#....", "#....", "#...."},
+	's': {".....", ".....", ".####", "#....", ".###.", "....#", "####."},
+	't': {".#...", ".#...", "####.", ".#...", ".#...", ".#..#", "..##."},
+	'u': {".....", ".....", "#...#", "#...#", "#...#", "#..##", ".##.#"},
+	'v': {".....", ".....", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'w': {".....", ".....", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'x': {".....", ".....", "#...#", ".#.#.", "..#..", ".#.#.", "#...#"},
+	'y': {".....", "#...#", "#...#", "#...#", ".####", "....#", ".###."},
+	'z': {".....", ".....", "#####", "...#.", "..#..", ".#...", "#####"},
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'#': {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'(': {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')': {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'.': {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'/': {".....", "....#", "...#.", "..#..", ".#...", "#....", "....."},
+	'%': {"##...", "##..#", "...#.", "..#..", ".#...", "#..##", "...##"},
+	' ': {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+}
+
+// drawText draws s onto img with its top-left corner at (x, y). Unknown runes
+// are skipped but still advance the cursor.
+func drawText(img *image.RGBA, x, y int, s string, c color.Color) {
+	for _, r := range s {
+		glyph, ok := glyphs[r]
+		if ok {
+			for row, line := range glyph {
+				for col, px := range line {
+					if px == '#' {
+						img.Set(x+col, y+row, c)
+					}
+				}
+			}
+		}
+		x += glyphAdvance
+	}
+}
+
+// textWidth returns the width in pixels of s when drawn with drawText.
+func textWidth(s string) int {
+	return len([]rune(s)) * glyphAdvance
+}
@@ -7,6 +7,7 @@ import (
 )
 
 func (c *ColorDetails) generateBg() {
+	c.Anchor = 600
 	c.Shades[600] = Details{Oklch: c.Base}
 	hue := c.Base.H
 
@@ -54,6 +55,9 @@ func (c *ColorDetails) generateBg() {
 	}
 }
 
+// TODO: 500-950 fade from 400 toward bgc50, so the scale gets lighter again
+// whenever bgc50 is lighter than 400, as on light seeds. Make it monotonic and
+// check Surface in TestGenerateMonotonicLightness.
 func (c *ColorDetails) generateFg(bgc50 oklab.Oklch) {
 	baseFg := c.Base
 	if baseFg.L <= 0.5 {
@@ -61,6 +65,7 @@ func (c *ColorDetails) generateFg(bgc50 oklab.Oklch) {
 	} else {
 		baseFg = oklab.Oklch{L: 0.25, C: baseFg.C, H: baseFg.H}
 	}
+	c.Anchor = 400
 	c.Shades[400] = Details{Oklch: baseFg}
 	hue := baseFg.H
 
@@ -121,6 +126,7 @@ func (c *ColorDetails) generateFg(bgc50 oklab.Oklch) {
 }
 
 func (c *ColorDetails) generateColor() {
+	c.Anchor = 600
 	c.Shades[600] = Details{Oklch: c.Base}
 	hue := c.Base.H
 
@@ -213,6 +219,7 @@ func (c *ColorDetails) generateBW(color Color) {
 		return
 	}
 
+	c.Anchor = baseShadeValue
 	c.Shades[baseShadeValue] = Details{Oklch: oklab.Oklch{L: baseL, C: fixedChroma, H: fixedHue}}
 
 	indexBase := float64(shadesMap[baseShadeValue])
@@ -240,6 +247,7 @@ func (c *ColorDetails) generateBW(color Color) {
 }
 
 func (c *ColorDetails) generateGrey() {
+	c.Anchor = 500
 	c.Shades[500] = Details{Oklch: c.Base}
 	hue := c.Base.H
 	baseOklch := c.Base
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"slices"
 
 	"github.com/alltom/oklab"
 )
@@ -86,7 +87,9 @@ type Details struct {
 type ColorDetails struct {
 	Color  Color
 	Base   oklab.Oklch
+	Anchor int // shade the generator pinned to Base (e.g., 600 or 500)
 	Shades map[int]Details
+	Alphas []int // opacity steps in percent emitted per shade (e.g., 20 → --base-600-a20)
 }
 
 type Palette map[Color]*ColorDetails
@@ -284,6 +287,10 @@ func (c ColorDetails) ToCSS(w io.Writer, color Color) {
 		}
 		css := OklchToString(&shade.Oklch)
 		fmt.Fprintf(w, "  --%s-%d: %s;\n", string(color), shadeKey, css)
+		for _, alpha := range c.Alphas {
+			css := OklchAlphaToString(&shade.Oklch, float64(alpha)/100)
+			fmt.Fprintf(w, "  --%s-%d-a%d: %s;\n", string(color), shadeKey, alpha, css)
+		}
 	}
 	fmt.Fprintln(w, "")
 }
@@ -296,10 +303,32 @@ func (c ColorDetails) ToTheme(w io.Writer, color Color) {
 		}
 		rootVar := fmt.Sprintf("var(--%s-%d)", string(color), shadeKey)
 		fmt.Fprintf(w, "  --color-%s-%d: %s;\n", string(color), shadeKey, rootVar)
+		for _, alpha := range c.Alphas {
+			rootVar := fmt.Sprintf("var(--%s-%d-a%d)", string(color), shadeKey, alpha)
+			fmt.Fprintf(w, "  --color-%s-%d-a%d: %s;\n", string(color), shadeKey, alpha, rootVar)
+		}
 	}
 	fmt.Fprintln(w, "")
 }
 
+// WithAlphas sets the opacity steps (in percent, 1-99) emitted for every shade
+// of every scale, in ascending order. Steps outside that range and repeated
+// steps are dropped.
+func (p Palette) WithAlphas(alphas ...int) Palette {
+	var steps []int
+	for _, alpha := range alphas {
+		if alpha > 0 && alpha < 100 {
+			steps = append(steps, alpha)
+		}
+	}
+	slices.Sort(steps)
+	steps = slices.Compact(steps)
+	for _, details := range p {
+		details.Alphas = steps
+	}
+	return p
+}
+
 func (m Mappings) ToMotifs(w io.Writer) {
 	for motif, pair := range m {
 		for _, shadeKey := range shades {
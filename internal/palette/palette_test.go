@@ -0,0 +1,141 @@
+package palette
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alltom/oklab"
+)
+
+// seeds samples the 24-bit space plus a few edge cases.
+func seeds() []string {
+	s := []string{"#000000", "#ffffff", "#222536", "#808080", "#f0e6d2", "#ff0000"}
+	for v := 0; v < 1<<24; v += 1 << 24 / 64 {
+		s = append(s, fmt.Sprintf("#%06x", (v+0x0f0f0f)&0xffffff))
+	}
+	return s
+}
+
+func TestGenerateComplete(t *testing.T) {
+	p := Generate("#222536")
+	for _, code := range orderedColors {
+		details, ok := p[code]
+		if !ok {
+			t.Fatalf("missing scale %s", code)
+		}
+		if _, ok := details.Shades[details.Anchor]; !ok {
+			t.Errorf("%s: anchor shade %d missing", code, details.Anchor)
+		}
+		for _, shade := range shades {
+			if _, ok := details.Shades[shade]; !ok {
+				t.Errorf("%s: missing shade %d", code, shade)
+			}
+		}
+	}
+}
+
+func TestGenerateMonotonicLightness(t *testing.T) {
+	for _, seed := range seeds() {
+		p := Generate(seed)
+		for _, code := range orderedColors {
+			if code != Surface { // see the TODO on generateFg
+				checkMonotonic(t, seed, p[code])
+			}
+		}
+	}
+}
+
+// checkMonotonic reports the shades of the scale lighter than the one before.
+func checkMonotonic(t *testing.T, seed string, details *ColorDetails) {
+	t.Helper()
+	const epsilon = 1e-9
+	for i := 1; i < len(shades); i++ {
+		prev, cur := details.Shades[shades[i-1]], details.Shades[shades[i]]
+		if cur.L > prev.L+epsilon {
+			t.Errorf("seed %s: %s-%d L=%.4f lighter than %s-%d L=%.4f",
+				seed, details.Color, shades[i], cur.L, details.Color, shades[i-1], prev.L)
+		}
+	}
+}
+
+func TestEqualize(t *testing.T) {
+	p := Generate("#222536")
+	before := make(map[Color]Details)
+	for code, details := range p {
+		before[code] = details.Shades[details.Anchor]
+	}
+	p.Equalize()
+
+	for _, u := range p.Uniformity(DefaultTolerance) {
+		details := p[u.Color]
+		if got := details.Shades[details.Anchor]; got != before[u.Color] {
+			t.Errorf("%s: anchor %d moved from %+v to %+v", u.Color, details.Anchor, before[u.Color], got)
+		}
+
+		// within each side of the anchor every step should now be (nearly) equal;
+		// stops are spaced by arc length, so chords across a bend come up short
+		for _, side := range [][2]int{{0, shadesMap[details.Anchor]}, {shadesMap[details.Anchor], len(shades) - 1}} {
+			steps := u.Steps[side[0]:side[1]]
+			for _, step := range steps {
+				if math.Abs(step.DeltaE-steps[0].DeltaE) > steps[0].DeltaE*0.01 {
+					t.Errorf("%s: step %d→%d ΔE=%.5f, want %.5f", u.Color, step.From, step.To, step.DeltaE, steps[0].DeltaE)
+				}
+			}
+		}
+	}
+}
+
+func TestWithAlphas(t *testing.T) {
+	p := Generate("#222536").WithAlphas(50, 20, 0, 100, 20, -5, 99, 50)
+	for code, details := range p {
+		if want := []int{20, 50, 99}; !slices.Equal(details.Alphas, want) {
+			t.Fatalf("%s: Alphas = %v, want %v", code, details.Alphas, want)
+		}
+	}
+	if p := Generate("#222536").WithAlphas(); p[Base].Alphas != nil {
+		t.Errorf("no steps: Alphas = %v", p[Base].Alphas)
+	}
+}
+
+func TestAlphaCSS(t *testing.T) {
+	c := ColorDetails{
+		Shades: map[int]Details{600: {Oklch: oklab.Oklch{L: 0.3, C: 0.04, H: math.Pi}}},
+		Alphas: []int{5, 20},
+	}
+
+	var css strings.Builder
+	c.ToCSS(&css, Base)
+	wantCSS := "" +
+		"  --base-600: oklch(0.30 0.040 180.00);\n" +
+		"  --base-600-a5: oklch(0.30 0.040 180.00 / 0.05);\n" +
+		"  --base-600-a20: oklch(0.30 0.040 180.00 / 0.20);\n" +
+		"\n"
+	if css.String() != wantCSS {
+		t.Errorf("ToCSS() =\n%s\nwant\n%s", css.String(), wantCSS)
+	}
+
+	var theme strings.Builder
+	c.ToTheme(&theme, Base)
+	wantTheme := "" +
+		"  --color-base-600: var(--base-600);\n" +
+		"  --color-base-600-a5: var(--base-600-a5);\n" +
+		"  --color-base-600-a20: var(--base-600-a20);\n" +
+		"\n"
+	if theme.String() != wantTheme {
+		t.Errorf("ToTheme() =\n%s\nwant\n%s", theme.String(), wantTheme)
+	}
+
+	// repeated steps are emitted once
+	p := Palette{Base: &ColorDetails{Shades: c.Shades}}.WithAlphas(20, 20, 5)
+	var out strings.Builder
+	p.ToCSS(&out)
+	if n := strings.Count(out.String(), "--base-600-a20:"); n != 1 {
+		t.Errorf("ToCSS() declares --base-600-a20 %d times, want once", n)
+	}
+	if n := strings.Count(out.String(), "--color-base-600-a20:"); n != 1 {
+		t.Errorf("ToCSS() declares --color-base-600-a20 %d times, want once", n)
+	}
+}
@@ -0,0 +1,365 @@
+package palette
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// === Models ==================================================================
+
+// Format is a tooling color scheme format the palette can be exported to.
+type Format string
+
+const (
+	Alacritty Format = "alacritty"
+	Kitty     Format = "kitty"
+	WezTerm   Format = "wezterm"
+	VSCode    Format = "vscode"
+	Neovim    Format = "nvim"
+)
+
+var Formats = []Format{Alacritty, Kitty, WezTerm, VSCode, Neovim}
+
+// Scheme maps the palette onto the roles editors and terminals care about. All
+// values are hex strings, since that is the common denominator of the formats.
+type Scheme struct {
+	Name string
+	Dark bool
+
+	Background    string
+	Foreground    string
+	Cursor        string
+	Selection     string
+	LineHighlight string
+	Panel         string
+	Border        string
+	Muted         string
+
+	// black, red, green, yellow, blue, magenta, cyan, white
+	Normal [8]string
+	Bright [8]string
+
+	Keyword  string
+	String   string
+	Number   string
+	Function string
+	Type     string
+	Constant string
+	Comment  string
+	Error    string
+	Warning  string
+	Info     string
+}
+
+// === Handlers ================================================================
+
+// Scheme builds a Scheme from the palette. Base and Surface provide the
+// background and foreground, the motif scales provide the accents, so every
+// export of the same seed shares one look.
+func (p Palette) Scheme(name string) Scheme {
+	dark := p[Base].Base.L <= 0.5
+	hex := func(color Color, shade int) string {
+		details, ok := p[color]
+		if !ok {
+			return "#000000"
+		}
+		oklch := details.Shades[shade].Oklch
+		return OklchToHex(&oklch)
+	}
+
+	// accents are lighter on dark backgrounds, darker on light ones
+	normal, bright := 400, 300
+	if !dark {
+		normal, bright = 700, 800
+	}
+	motif := func(m Motif, shade int) string {
+		return hex(mappings[m].Alpha, shade)
+	}
+
+	s := Scheme{
+		Name:          name,
+		Dark:          dark,
+		Background:    hex(Base, 600),
+		Foreground:    hex(Surface, 400),
+		Cursor:        motif(Primary, normal),
+		Selection:     hex(Base, 400),
+		LineHighlight: hex(Base, 500),
+		Panel:         hex(Base, 700),
+		Border:        hex(Base, 800),
+		Muted:         motif(Subtle, 500),
+
+		Keyword:  motif(Primary, normal),
+		String:   motif(Success, normal),
+		Number:   motif(Accent, normal),
+		Function: motif(Info, normal),
+		Type:     motif(In, normal),
+		Constant: motif(Warning, normal),
+		Comment:  motif(Subtle, 500),
+		Error:    motif(Error, normal),
+		Warning:  motif(Warning, normal),
+		Info:     motif(Info, normal),
+	}
+
+	accents := []Motif{Error, Success, Warning, Primary, In, Info}
+	for i, m := range accents {
+		s.Normal[i+1] = motif(m, normal)
+		s.Bright[i+1] = motif(m, bright)
+	}
+	if dark {
+		s.Normal[0], s.Bright[0] = hex(Base, 800), hex(Base, 400)
+		s.Normal[7], s.Bright[7] = hex(Surface, 300), hex(Surface, 50)
+	} else {
+		s.Normal[0], s.Bright[0] = hex(Surface, 400), hex(Surface, 300)
+		s.Normal[7], s.Bright[7] = hex(Base, 700), hex(Base, 500)
+	}
+
+	return s
+}
+
+// Write writes the scheme in the given format.
+func (s Scheme) Write(w io.Writer, format Format) error {
+	switch format {
+	case Alacritty:
+		return s.ToAlacritty(w)
+	case Kitty:
+		return s.ToKitty(w)
+	case WezTerm:
+		return s.ToWezTerm(w)
+	case VSCode:
+		return s.ToVSCode(w)
+	case Neovim:
+		return s.ToNeovim(w)
+	default:
+		return fmt.Errorf("unknown format %q, expected one of %v", format, Formats)
+	}
+}
+
+var ansiNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// ToAlacritty writes an alacritty.toml colors section.
+func (s Scheme) ToAlacritty(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", s.Name)
+	fmt.Fprintln(&sb, "[colors.primary]")
+	fmt.Fprintf(&sb, "background = %q\n", s.Background)
+	fmt.Fprintf(&sb, "foreground = %q\n\n", s.Foreground)
+	fmt.Fprintln(&sb, "[colors.cursor]")
+	fmt.Fprintf(&sb, "cursor = %q\n", s.Cursor)
+	fmt.Fprintf(&sb, "text = %q\n\n", s.Background)
+	fmt.Fprintln(&sb, "[colors.selection]")
+	fmt.Fprintf(&sb, "background = %q\n", s.Selection)
+	fmt.Fprintf(&sb, "text = %q\n", s.Foreground)
+	for _, group := range []struct {
+		name   string
+		colors [8]string
+	}{{"normal", s.Normal}, {"bright", s.Bright}} {
+		fmt.Fprintf(&sb, "\n[colors.%s]\n", group.name)
+		for i, name := range ansiNames {
+			fmt.Fprintf(&sb, "%s = %q\n", name, group.colors[i])
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ToKitty writes a kitty theme .conf file.
+func (s Scheme) ToKitty(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## name: %s\n\n", s.Name)
+	fmt.Fprintf(&sb, "background %s\n", s.Background)
+	fmt.Fprintf(&sb, "foreground %s\n", s.Foreground)
+	fmt.Fprintf(&sb, "cursor %s\n", s.Cursor)
+	fmt.Fprintf(&sb, "cursor_text_color %s\n", s.Background)
+	fmt.Fprintf(&sb, "selection_background %s\n", s.Selection)
+	fmt.Fprintf(&sb, "selection_foreground %s\n", s.Foreground)
+	fmt.Fprintf(&sb, "active_border_color %s\n", s.Cursor)
+	fmt.Fprintf(&sb, "inactive_border_color %s\n", s.Border)
+	fmt.Fprintf(&sb, "url_color %s\n\n", s.Function)
+	for i := range ansiNames {
+		fmt.Fprintf(&sb, "color%d %s\n", i, s.Normal[i])
+	}
+	for i := range ansiNames {
+		fmt.Fprintf(&sb, "color%d %s\n", i+8, s.Bright[i])
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ToWezTerm writes a WezTerm color scheme .toml file.
+func (s Scheme) ToWezTerm(w io.Writer) error {
+	quote := func(colors [8]string) string {
+		quoted := make([]string, len(colors))
+		for i, c := range colors {
+			quoted[i] = fmt.Sprintf("%q", c)
+		}
+		return strings.Join(quoted, ", ")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintln(&sb, "[colors]")
+	fmt.Fprintf(&sb, "background = %q\n", s.Background)
+	fmt.Fprintf(&sb, "foreground = %q\n", s.Foreground)
+	fmt.Fprintf(&sb, "cursor_bg = %q\n", s.Cursor)
+	fmt.Fprintf(&sb, "cursor_border = %q\n", s.Cursor)
+	fmt.Fprintf(&sb, "cursor_fg = %q\n", s.Background)
+	fmt.Fprintf(&sb, "selection_bg = %q\n", s.Selection)
+	fmt.Fprintf(&sb, "selection_fg = %q\n", s.Foreground)
+	fmt.Fprintf(&sb, "split = %q\n", s.Border)
+	fmt.Fprintf(&sb, "ansi = [%s]\n", quote(s.Normal))
+	fmt.Fprintf(&sb, "brights = [%s]\n\n", quote(s.Bright))
+	fmt.Fprintln(&sb, "[metadata]")
+	fmt.Fprintf(&sb, "name = %q\n", s.Name)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type vscodeTokenColor struct {
+	Name     string            `json:"name"`
+	Scope    []string          `json:"scope"`
+	Settings map[string]string `json:"settings"`
+}
+
+type vscodeTheme struct {
+	Name        string             `json:"name"`
+	Type        string             `json:"type"`
+	Colors      map[string]string  `json:"colors"`
+	TokenColors []vscodeTokenColor `json:"tokenColors"`
+}
+
+// ToVSCode writes a VS Code color theme JSON file.
+func (s Scheme) ToVSCode(w io.Writer) error {
+	theme := vscodeTheme{Name: s.Name, Type: "light", Colors: map[string]string{
+		"editor.background":                 s.Background,
+		"editor.foreground":                 s.Foreground,
+		"editor.selectionBackground":        s.Selection,
+		"editor.lineHighlightBackground":    s.LineHighlight,
+		"editorCursor.foreground":           s.Cursor,
+		"editorLineNumber.foreground":       s.Muted,
+		"editorLineNumber.activeForeground": s.Foreground,
+		"editorError.foreground":            s.Error,
+		"editorWarning.foreground":          s.Warning,
+		"editorInfo.foreground":             s.Info,
+		"focusBorder":                       s.Cursor,
+		"sideBar.background":                s.Panel,
+		"sideBar.foreground":                s.Foreground,
+		"activityBar.background":            s.Panel,
+		"activityBar.foreground":            s.Foreground,
+		"titleBar.activeBackground":         s.Panel,
+		"titleBar.activeForeground":         s.Foreground,
+		"statusBar.background":              s.Panel,
+		"statusBar.foreground":              s.Foreground,
+		"panel.background":                  s.Panel,
+		"panel.border":                      s.Border,
+		"editorGroup.border":                s.Border,
+		"tab.activeBackground":              s.Background,
+		"tab.inactiveBackground":            s.Panel,
+		"terminal.background":               s.Background,
+		"terminal.foreground":               s.Foreground,
+	}}
+	if s.Dark {
+		theme.Type = "dark"
+	}
+
+	terminalNames := [8]string{"Black", "Red", "Green", "Yellow", "Blue", "Magenta", "Cyan", "White"}
+	for i, name := range terminalNames {
+		theme.Colors["terminal.ansi"+name] = s.Normal[i]
+		theme.Colors["terminal.ansiBright"+name] = s.Bright[i]
+	}
+
+	token := func(name, color string, scope ...string) vscodeTokenColor {
+		return vscodeTokenColor{Name: name, Scope: scope, Settings: map[string]string{"foreground": color}}
+	}
+	theme.TokenColors = []vscodeTokenColor{
+		token("Comment", s.Comment, "comment", "punctuation.definition.comment"),
+		token("Keyword", s.Keyword, "keyword", "storage.type", "storage.modifier"),
+		token("String", s.String, "string"),
+		token("Number", s.Number, "constant.numeric"),
+		token("Constant", s.Constant, "constant.language", "constant.character", "variable.other.constant"),
+		token("Function", s.Function, "entity.name.function", "support.function"),
+		token("Type", s.Type, "entity.name.type", "support.type", "entity.name.class"),
+		token("Variable", s.Foreground, "variable", "meta.definition.variable"),
+		token("Invalid", s.Error, "invalid"),
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(theme)
+}
+
+// ToNeovim writes a Neovim Lua colorscheme, suitable for colors/<name>.lua.
+func (s Scheme) ToNeovim(w io.Writer) error {
+	background := "light"
+	if s.Dark {
+		background = "dark"
+	}
+
+	type hl struct{ fg, bg string }
+	groups := map[string]hl{
+		"Normal":          {s.Foreground, s.Background},
+		"NormalFloat":     {s.Foreground, s.Panel},
+		"FloatBorder":     {s.Border, s.Panel},
+		"Cursor":          {s.Background, s.Cursor},
+		"CursorLine":      {"", s.LineHighlight},
+		"Visual":          {"", s.Selection},
+		"LineNr":          {s.Muted, ""},
+		"CursorLineNr":    {s.Foreground, ""},
+		"SignColumn":      {"", s.Background},
+		"StatusLine":      {s.Foreground, s.Panel},
+		"StatusLineNC":    {s.Muted, s.Panel},
+		"WinSeparator":    {s.Border, ""},
+		"Pmenu":           {s.Foreground, s.Panel},
+		"PmenuSel":        {s.Foreground, s.Selection},
+		"Search":          {s.Background, s.Warning},
+		"Comment":         {s.Comment, ""},
+		"Keyword":         {s.Keyword, ""},
+		"Statement":       {s.Keyword, ""},
+		"String":          {s.String, ""},
+		"Number":          {s.Number, ""},
+		"Constant":        {s.Constant, ""},
+		"Function":        {s.Function, ""},
+		"Type":            {s.Type, ""},
+		"Identifier":      {s.Foreground, ""},
+		"Error":           {s.Error, ""},
+		"ErrorMsg":        {s.Error, ""},
+		"WarningMsg":      {s.Warning, ""},
+		"DiagnosticError": {s.Error, ""},
+		"DiagnosticWarn":  {s.Warning, ""},
+		"DiagnosticInfo":  {s.Info, ""},
+		"DiagnosticHint":  {s.Muted, ""},
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "-- %s\n", s.Name)
+	fmt.Fprintln(&sb, "vim.cmd(\"highlight clear\")")
+	fmt.Fprintf(&sb, "vim.o.background = %q\n", background)
+	fmt.Fprintf(&sb, "vim.g.colors_name = %q\n\n", s.Name)
+	fmt.Fprintln(&sb, "local set = vim.api.nvim_set_hl")
+	for _, name := range names {
+		group := groups[name]
+		var attrs []string
+		if group.fg != "" {
+			attrs = append(attrs, fmt.Sprintf("fg = %q", group.fg))
+		}
+		if group.bg != "" {
+			attrs = append(attrs, fmt.Sprintf("bg = %q", group.bg))
+		}
+		fmt.Fprintf(&sb, "set(0, %q, { %s })\n", name, strings.Join(attrs, ", "))
+	}
+	fmt.Fprintln(&sb)
+	for i := range ansiNames {
+		fmt.Fprintf(&sb, "vim.g.terminal_color_%d = %q\n", i, s.Normal[i])
+	}
+	for i := range ansiNames {
+		fmt.Fprintf(&sb, "vim.g.terminal_color_%d = %q\n", i+8, s.Bright[i])
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
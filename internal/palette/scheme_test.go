@@ -0,0 +1,159 @@
+package palette
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/alltom/oklab"
+)
+
+var hexColor = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+func TestScheme(t *testing.T) {
+	for seed, dark := range map[string]bool{"#222536": true, "#f0e6d2": false} {
+		s := Generate(seed).Scheme("hgmx")
+		if s.Dark != dark {
+			t.Errorf("seed %s: Dark = %v, want %v", seed, s.Dark, dark)
+		}
+		for _, c := range append([]string{
+			s.Background, s.Foreground, s.Cursor, s.Selection, s.LineHighlight, s.Panel, s.Border, s.Muted,
+			s.Keyword, s.String, s.Number, s.Function, s.Type, s.Constant, s.Comment, s.Error, s.Warning, s.Info,
+		}, append(s.Normal[:], s.Bright[:]...)...) {
+			if !hexColor.MatchString(c) {
+				t.Fatalf("seed %s: scheme has color %q, want #rrggbb\n%+v", seed, c, s)
+			}
+		}
+		if s.Background == s.Foreground {
+			t.Errorf("seed %s: background and foreground are both %s", seed, s.Background)
+		}
+		if ratio := ContrastRatio(mustHex(t, s.Foreground), mustHex(t, s.Background)); ratio < 4.5 {
+			t.Errorf("seed %s: foreground contrast %.2f, want at least 4.5", seed, ratio)
+		}
+	}
+}
+
+func TestSchemeFormats(t *testing.T) {
+	s := Generate("#222536").Scheme("hgmx")
+
+	write := func(f Format) string {
+		var sb strings.Builder
+		if err := s.Write(&sb, f); err != nil {
+			t.Fatalf("Write(%s): %v", f, err)
+		}
+		return sb.String()
+	}
+	contains := func(f Format, out string, want ...string) {
+		for _, w := range want {
+			if !strings.Contains(out, w) {
+				t.Errorf("%s output is missing %q:\n%s", f, w, out)
+			}
+		}
+	}
+
+	contains(Alacritty, write(Alacritty),
+		"# hgmx\n",
+		"[colors.primary]\nbackground = \""+s.Background+"\"\nforeground = \""+s.Foreground+"\"\n",
+		"[colors.cursor]\ncursor = \""+s.Cursor+"\"\n",
+		"[colors.normal]\nblack = \""+s.Normal[0]+"\"\nred = \""+s.Normal[1]+"\"\n",
+		"[colors.bright]\n",
+		"white = \""+s.Bright[7]+"\"\n",
+	)
+
+	kitty := write(Kitty)
+	contains(Kitty, kitty,
+		"## name: hgmx\n",
+		"background "+s.Background+"\n",
+		"selection_background "+s.Selection+"\n",
+		"color0 "+s.Normal[0]+"\n",
+		"color15 "+s.Bright[7]+"\n",
+	)
+	if n := regexp.MustCompile(`(?m)^color\d+ `).FindAllString(kitty, -1); len(n) != 16 {
+		t.Errorf("kitty output has %d colorN lines, want 16", len(n))
+	}
+
+	quoted := func(colors [8]string) string {
+		q := make([]string, len(colors))
+		for i, c := range colors {
+			q[i] = fmt.Sprintf("%q", c)
+		}
+		return strings.Join(q, ", ")
+	}
+	contains(WezTerm, write(WezTerm),
+		"[colors]\nbackground = \""+s.Background+"\"\n",
+		"ansi = ["+quoted(s.Normal)+"]\n",
+		"brights = ["+quoted(s.Bright)+"]\n",
+		"[metadata]\nname = \"hgmx\"\n",
+	)
+
+	var theme vscodeTheme
+	if err := json.Unmarshal([]byte(write(VSCode)), &theme); err != nil {
+		t.Fatalf("vscode output is not JSON: %v", err)
+	}
+	if theme.Name != "hgmx" || theme.Type != "dark" {
+		t.Errorf("vscode theme %q of type %q, want hgmx, dark", theme.Name, theme.Type)
+	}
+	for key, want := range map[string]string{
+		"editor.background":         s.Background,
+		"editor.foreground":         s.Foreground,
+		"terminal.ansiRed":          s.Normal[1],
+		"terminal.ansiBrightWhite":  s.Bright[7],
+		"editorError.foreground":    s.Error,
+		"titleBar.activeBackground": s.Panel,
+	} {
+		if got := theme.Colors[key]; got != want {
+			t.Errorf("vscode %s = %q, want %q", key, got, want)
+		}
+	}
+	if len(theme.TokenColors) == 0 || theme.TokenColors[0].Settings["foreground"] != s.Comment {
+		t.Errorf("vscode token colors = %+v", theme.TokenColors)
+	}
+
+	nvim := write(Neovim)
+	contains(Neovim, nvim,
+		"-- hgmx\n",
+		"vim.o.background = \"dark\"\n",
+		"vim.g.colors_name = \"hgmx\"\n",
+		"set(0, \"Normal\", { fg = \""+s.Foreground+"\", bg = \""+s.Background+"\" })\n",
+		"set(0, \"CursorLine\", { bg = \""+s.LineHighlight+"\" })\n",
+		"vim.g.terminal_color_0 = \""+s.Normal[0]+"\"\n",
+		"vim.g.terminal_color_15 = \""+s.Bright[7]+"\"\n",
+	)
+	if strings.Index(nvim, `"Comment"`) > strings.Index(nvim, `"Normal"`) {
+		t.Error("neovim highlight groups are not sorted")
+	}
+
+	if err := s.Write(&strings.Builder{}, "emacs"); err == nil {
+		t.Error("Write accepted an unknown format")
+	}
+}
+
+func TestSchemeLight(t *testing.T) {
+	s := Generate("#f0e6d2").Scheme("paper")
+
+	var sb strings.Builder
+	if err := s.ToNeovim(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "vim.o.background = \"light\"\n") {
+		t.Errorf("light scheme sets:\n%s", sb.String())
+	}
+	sb.Reset()
+	if err := s.ToVSCode(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), `"type": "light"`) {
+		t.Errorf("light vscode theme:\n%s", sb.String())
+	}
+}
+
+func mustHex(t *testing.T, hex string) oklab.Oklch {
+	t.Helper()
+	c, err := HexToOklch(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
@@ -0,0 +1,212 @@
+package palette
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/alltom/oklab"
+)
+
+// === Terminal ================================================================
+
+// ToANSI prints every scale as a row of terminal swatches. When truecolor is
+// false the swatches fall back to the nearest xterm 256-color index.
+func (p Palette) ToANSI(w io.Writer, truecolor bool) {
+	fmt.Fprintf(w, "%-9s", "")
+	for _, shadeKey := range shades {
+		fmt.Fprintf(w, " %-4d", shadeKey)
+	}
+	fmt.Fprintln(w)
+
+	for _, code := range orderedColors {
+		colorDetails, ok := p[code]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "%-9s", string(code))
+		for _, shadeKey := range shades {
+			shade, ok := colorDetails.Shades[shadeKey]
+			if !ok {
+				fmt.Fprint(w, "     ")
+				continue
+			}
+			r, g, b := rgb8(shade.Oklch)
+			if truecolor {
+				fmt.Fprintf(w, " \x1b[48;2;%d;%d;%dm    \x1b[0m", r, g, b)
+			} else {
+				fmt.Fprintf(w, " \x1b[48;5;%dm    \x1b[0m", ansi256(r, g, b))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// ansi256 returns the xterm 256-color index closest to the given sRGB color,
+// choosing between the 6x6x6 color cube and the 24 step grayscale ramp.
+func ansi256(r, g, b uint8) int {
+	cube := func(v uint8) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return int(v-35) / 40
+	}
+	level := func(i int) int {
+		if i == 0 {
+			return 0
+		}
+		return 55 + i*40
+	}
+	dist := func(r2, g2, b2 int) int {
+		dr, dg, db := int(r)-r2, int(g)-g2, int(b)-b2
+		return dr*dr + dg*dg + db*db
+	}
+
+	ri, gi, bi := cube(r), cube(g), cube(b)
+	cubeIndex := 16 + 36*ri + 6*gi + bi
+	cubeDist := dist(level(ri), level(gi), level(bi))
+
+	avg := (int(r) + int(g) + int(b)) / 3
+	grayIndex := 23
+	if avg < 238 {
+		grayIndex = max(0, (avg-3)/10)
+	}
+	grayLevel := 8 + grayIndex*10
+	grayDist := dist(grayLevel, grayLevel, grayLevel)
+
+	if grayDist < cubeDist {
+		return 232 + grayIndex
+	}
+	return cubeIndex
+}
+
+// === Images ==================================================================
+
+const (
+	swatchMargin     = 8
+	swatchLabelWidth = 64
+	swatchHeader     = 16
+	swatchWidth      = 156
+	swatchHeight     = 40
+	swatchPadding    = 6
+)
+
+var (
+	swatchBackground = oklab.Oklch{L: 1}
+	swatchInk        = oklab.Oklch{L: 0}
+)
+
+// ToPNG renders the palette as a labelled grid of swatches, one row per scale,
+// with the hex and OKLCH value of each shade printed on the swatch.
+func (p Palette) ToPNG(w io.Writer) error {
+	codes := p.codes()
+	width := 2*swatchMargin + swatchLabelWidth + len(shades)*swatchWidth
+	height := 2*swatchMargin + swatchHeader + len(codes)*swatchHeight
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(toRGBA(swatchBackground)), image.Point{}, draw.Src)
+
+	ink := toRGBA(swatchInk)
+	for i, shadeKey := range shades {
+		x := swatchMargin + swatchLabelWidth + i*swatchWidth + swatchPadding
+		drawText(img, x, swatchMargin+(swatchHeader-glyphHeight)/2, fmt.Sprint(shadeKey), ink)
+	}
+
+	for row, code := range codes {
+		y := swatchMargin + swatchHeader + row*swatchHeight
+		drawText(img, swatchMargin, y+(swatchHeight-glyphHeight)/2, string(code), ink)
+
+		for col, shadeKey := range shades {
+			shade, ok := p[code].Shades[shadeKey]
+			if !ok {
+				continue
+			}
+			x := swatchMargin + swatchLabelWidth + col*swatchWidth
+			rect := image.Rect(x, y, x+swatchWidth, y+swatchHeight)
+			draw.Draw(img, rect, image.NewUniform(toRGBA(shade.Oklch)), image.Point{}, draw.Src)
+
+			label := toRGBA(labelColor(shade.Oklch))
+			drawText(img, x+swatchPadding, y+swatchPadding+2, OklchToHex(&shade.Oklch), label)
+			drawText(img, x+swatchPadding, y+swatchHeight-swatchPadding-glyphHeight-2, OklchToString(&shade.Oklch), label)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// ToSVG renders the same labelled swatch grid as ToPNG as an SVG document.
+func (p Palette) ToSVG(w io.Writer) error {
+	codes := p.codes()
+	width := 2*swatchMargin + swatchLabelWidth + len(shades)*swatchWidth
+	height := 2*swatchMargin + swatchHeader + len(codes)*swatchHeight
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"monospace\" font-size=\"10\">\n", width, height, width, height)
+	fmt.Fprintf(&sb, "  <rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", OklchToHex(&swatchBackground))
+
+	ink := OklchToHex(&swatchInk)
+	for i, shadeKey := range shades {
+		x := swatchMargin + swatchLabelWidth + i*swatchWidth + swatchPadding
+		fmt.Fprintf(&sb, "  <text x=\"%d\" y=\"%d\" fill=\"%s\">%d</text>\n", x, swatchMargin+swatchHeader-4, ink, shadeKey)
+	}
+
+	for row, code := range codes {
+		y := swatchMargin + swatchHeader + row*swatchHeight
+		fmt.Fprintf(&sb, "  <g id=\"%s\">\n", code)
+		fmt.Fprintf(&sb, "    <text x=\"%d\" y=\"%d\" fill=\"%s\">%s</text>\n", swatchMargin, y+swatchHeight/2+4, ink, code)
+
+		for col, shadeKey := range shades {
+			shade, ok := p[code].Shades[shadeKey]
+			if !ok {
+				continue
+			}
+			x := swatchMargin + swatchLabelWidth + col*swatchWidth
+			hex := OklchToHex(&shade.Oklch)
+			label := labelColor(shade.Oklch)
+			fmt.Fprintf(&sb, "    <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", x, y, swatchWidth, swatchHeight, hex)
+			fmt.Fprintf(&sb, "    <text x=\"%d\" y=\"%d\" fill=\"%s\">%s</text>\n", x+swatchPadding, y+swatchPadding+10, OklchToHex(&label), hex)
+			fmt.Fprintf(&sb, "    <text x=\"%d\" y=\"%d\" fill=\"%s\">%s</text>\n", x+swatchPadding, y+swatchHeight-swatchPadding-2, OklchToHex(&label), OklchToString(&shade.Oklch))
+		}
+		fmt.Fprintln(&sb, "  </g>")
+	}
+	fmt.Fprintln(&sb, "</svg>")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// codes returns the scales present in the palette, in palette order.
+func (p Palette) codes() []Color {
+	var codes []Color
+	for _, code := range orderedColors {
+		if _, ok := p[code]; ok {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// labelColor picks black or white text, whichever contrasts more with c.
+func labelColor(c oklab.Oklch) oklab.Oklch {
+	if ContrastRatio(c, swatchInk) >= ContrastRatio(c, swatchBackground) {
+		return swatchInk
+	}
+	return swatchBackground
+}
+
+func rgb8(c oklab.Oklch) (r, g, b uint8) {
+	r32, g32, b32, _ := c.RGBA()
+	return uint8(r32 >> 8), uint8(g32 >> 8), uint8(b32 >> 8)
+}
+
+func toRGBA(c oklab.Oklch) color.RGBA {
+	r, g, b := rgb8(c)
+	return color.RGBA{R: r, G: g, B: b, A: 255}
+}
@@ -0,0 +1,189 @@
+package palette
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alltom/oklab"
+)
+
+// blackAndWhite is a one scale palette with a white 50 and a black 950.
+func blackAndWhite() Palette {
+	return Palette{Base: &ColorDetails{Color: Base, Shades: map[int]Details{
+		50:  {Oklch: oklab.Oklch{L: 1}},
+		950: {Oklch: oklab.Oklch{L: 0}},
+	}}}
+}
+
+func TestANSI256(t *testing.T) {
+	for _, tc := range []struct {
+		r, g, b uint8
+		want    int
+	}{
+		{0, 0, 0, 16},
+		{255, 255, 255, 231},
+		{255, 0, 0, 196},
+		{0, 0, 255, 21},
+		{95, 135, 175, 67},   // exact cube levels
+		{48, 115, 155, 67},   // cube thresholds: 48 → 1, 115 → 2
+		{128, 128, 128, 244}, // closer to the gray ramp than to the cube
+		{238, 238, 238, 255}, // top of the gray ramp
+		{100, 101, 99, 241},  // near gray stays gray
+		{250, 128, 114, 209}, // salmon
+	} {
+		if got := ansi256(tc.r, tc.g, tc.b); got != tc.want {
+			t.Errorf("ansi256(%d, %d, %d) = %d, want %d", tc.r, tc.g, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestToANSI(t *testing.T) {
+	p := blackAndWhite()
+
+	var truecolor, indexed strings.Builder
+	p.ToANSI(&truecolor, true)
+	p.ToANSI(&indexed, false)
+
+	lines := strings.Split(strings.TrimSuffix(truecolor.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "base") {
+		t.Fatalf("ToANSI() printed %q, want a header and one row", lines)
+	}
+	for _, shade := range shades {
+		if !strings.Contains(lines[0], " "+strconv.Itoa(shade)) {
+			t.Errorf("header %q is missing shade %d", lines[0], shade)
+		}
+	}
+	for _, want := range []string{"\x1b[48;2;255;255;255m    \x1b[0m", "\x1b[48;2;0;0;0m    \x1b[0m"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("truecolor row %q is missing %q", lines[1], want)
+		}
+	}
+	if n := strings.Count(lines[1], "\x1b[48;"); n != 2 {
+		t.Errorf("truecolor row has %d swatches, want 2 for the 2 shades", n)
+	}
+	for _, want := range []string{"\x1b[48;5;231m", "\x1b[48;5;16m"} {
+		if !strings.Contains(indexed.String(), want) {
+			t.Errorf("256-color output is missing %q:\n%q", want, indexed.String())
+		}
+	}
+	if strings.Contains(indexed.String(), "48;2;") {
+		t.Error("256-color output contains truecolor escapes")
+	}
+}
+
+func TestToPNG(t *testing.T) {
+	p := blackAndWhite()
+
+	var buf bytes.Buffer
+	if err := p.ToPNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("ToPNG() wrote an invalid PNG: %v", err)
+	}
+	wantW := 2*swatchMargin + swatchLabelWidth + len(shades)*swatchWidth
+	wantH := 2*swatchMargin + swatchHeader + swatchHeight
+	if b := img.Bounds(); b.Dx() != wantW || b.Dy() != wantH {
+		t.Fatalf("image is %dx%d, want %dx%d", b.Dx(), b.Dy(), wantW, wantH)
+	}
+
+	// sample each swatch at its right edge, clear of the labels
+	at := func(col int) color.Color {
+		x := swatchMargin + swatchLabelWidth + (col+1)*swatchWidth - 2
+		y := swatchMargin + swatchHeader + swatchHeight/2
+		return img.At(x, y)
+	}
+	if got := color.RGBAModel.Convert(at(shadesMap[950])); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("950 swatch = %v, want black", got)
+	}
+	if got := color.RGBAModel.Convert(at(shadesMap[500])); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("missing 500 swatch = %v, want the white background", got)
+	}
+
+	// the label on the black swatch is drawn in white
+	x0 := swatchMargin + swatchLabelWidth + shadesMap[950]*swatchWidth
+	y0 := swatchMargin + swatchHeader
+	white := 0
+	for y := y0; y < y0+swatchHeight; y++ {
+		for x := x0; x < x0+swatchWidth; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == (color.RGBA{255, 255, 255, 255}) {
+				white++
+			}
+		}
+	}
+	if white == 0 {
+		t.Error("black swatch has no white label")
+	}
+}
+
+func TestToSVG(t *testing.T) {
+	p := blackAndWhite()
+
+	var buf bytes.Buffer
+	if err := p.ToSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	dec := xml.NewDecoder(strings.NewReader(out))
+	var rects, groups int
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ToSVG() wrote invalid XML: %v\n%s", err, out)
+		}
+		if el, ok := tok.(xml.StartElement); ok {
+			switch el.Name.Local {
+			case "rect":
+				rects++
+			case "g":
+				groups++
+			}
+		}
+	}
+	if rects != 3 || groups != 1 {
+		t.Errorf("got %d rects and %d groups, want a background, 2 swatches and 1 group", rects, groups)
+	}
+
+	width := 2*swatchMargin + swatchLabelWidth + len(shades)*swatchWidth
+	x950 := swatchMargin + swatchLabelWidth + shadesMap[950]*swatchWidth
+	for _, want := range []string{
+		`width="` + strconv.Itoa(width) + `"`,
+		`<g id="base">`,
+		`<rect x="` + strconv.Itoa(x950) + `" y="` + strconv.Itoa(swatchMargin+swatchHeader) + `" width="156" height="40" fill="#000000"/>`,
+		`fill="#ffffff">#000000</text>`,
+		`>oklch(0.00 0.000 000.00)</text>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("ToSVG() is missing %s\n%s", want, out)
+		}
+	}
+}
+
+func TestGlyphs(t *testing.T) {
+	for r, glyph := range glyphs {
+		for _, row := range glyph {
+			if len(row) != glyphWidth || strings.Trim(row, ".#") != "" {
+				t.Errorf("glyph %q has malformed row %q", r, row)
+			}
+		}
+	}
+	for _, r := range "0123456789abcdef#()./-% oklch" {
+		if _, ok := glyphs[r]; !ok {
+			t.Errorf("no glyph for %q, used by swatch labels", r)
+		}
+	}
+	if w := textWidth("#ff00aa"); w != 7*glyphAdvance {
+		t.Errorf("textWidth = %d, want %d", w, 7*glyphAdvance)
+	}
+}
@@ -0,0 +1,191 @@
+package palette
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/alltom/oklab"
+)
+
+// DefaultTolerance is the relative deviation from a scale's mean step that is
+// still considered even (0.35 = ±35%).
+const DefaultTolerance = 0.35
+
+// Step is the perceptual distance between two adjacent shades of a scale.
+type Step struct {
+	From   int
+	To     int
+	DeltaE float64
+	DeltaL float64
+}
+
+// Uniformity summarizes how evenly a scale is spaced in Oklab.
+type Uniformity struct {
+	Color        Color
+	Anchor       int
+	Steps        []Step
+	Mean         float64
+	StdDev       float64
+	NonMonotonic []Step // lightness did not decrease from From to To
+	Uneven       []Step // DeltaE deviates from Mean by more than the tolerance
+}
+
+// OK reports whether the scale has no non-monotonic or uneven steps.
+func (u Uniformity) OK() bool {
+	return len(u.NonMonotonic) == 0 && len(u.Uneven) == 0
+}
+
+// DeltaEOK calculates the euclidean distance between two colors in Oklab.
+func DeltaEOK(c1, c2 oklab.Oklch) float64 {
+	return distance(c1.Oklab(), c2.Oklab())
+}
+
+func distance(a, b oklab.Oklab) float64 {
+	dL, dA, dB := a.L-b.L, a.A-b.A, a.B-b.B
+	return math.Sqrt(dL*dL + dA*dA + dB*dB)
+}
+
+// Uniformity measures the ΔE-OK steps along the scale from 50 to 950.
+func (c *ColorDetails) Uniformity(tolerance float64) Uniformity {
+	u := Uniformity{Color: c.Color, Anchor: c.Anchor}
+
+	for i := 1; i < len(shades); i++ {
+		from, okFrom := c.Shades[shades[i-1]]
+		to, okTo := c.Shades[shades[i]]
+		if !okFrom || !okTo {
+			continue
+		}
+		step := Step{
+			From:   shades[i-1],
+			To:     shades[i],
+			DeltaE: DeltaEOK(from.Oklch, to.Oklch),
+			DeltaL: to.L - from.L,
+		}
+		u.Steps = append(u.Steps, step)
+		u.Mean += step.DeltaE
+		if step.DeltaL >= 0 {
+			u.NonMonotonic = append(u.NonMonotonic, step)
+		}
+	}
+	if len(u.Steps) == 0 {
+		return u
+	}
+
+	u.Mean /= float64(len(u.Steps))
+	for _, step := range u.Steps {
+		u.StdDev += (step.DeltaE - u.Mean) * (step.DeltaE - u.Mean)
+		if math.Abs(step.DeltaE-u.Mean) > u.Mean*tolerance {
+			u.Uneven = append(u.Uneven, step)
+		}
+	}
+	u.StdDev = math.Sqrt(u.StdDev / float64(len(u.Steps)))
+
+	return u
+}
+
+// Uniformity measures every scale in the palette, in palette order.
+func (p Palette) Uniformity(tolerance float64) []Uniformity {
+	var report []Uniformity
+	for _, code := range orderedColors {
+		details, ok := p[code]
+		if ok {
+			report = append(report, details.Uniformity(tolerance))
+		}
+	}
+	return report
+}
+
+// Equalize redistributes the shades of the scale so adjacent stops are an equal
+// ΔE-OK apart. The 50 and 950 endpoints and the anchor shade are preserved, the
+// stops on either side of the anchor are re-spaced along the existing curve.
+func (c *ColorDetails) Equalize() {
+	anchor, ok := shadesMap[c.Anchor]
+	if !ok {
+		return
+	}
+	for _, shadeValue := range shades {
+		if _, ok := c.Shades[shadeValue]; !ok {
+			return
+		}
+	}
+
+	c.equalizeRange(0, anchor)
+	c.equalizeRange(anchor, len(shades)-1)
+}
+
+// Equalize redistributes the shades of every scale in the palette.
+func (p Palette) Equalize() {
+	for _, details := range p {
+		details.Equalize()
+	}
+}
+
+// equalizeRange re-spaces the shades strictly between shades[start] and
+// shades[end] to equal arc length along the polyline through the current stops.
+func (c *ColorDetails) equalizeRange(start, end int) {
+	if end-start < 2 {
+		return
+	}
+
+	points := make([]oklab.Oklab, 0, end-start+1)
+	lengths := []float64{0}
+	for i := start; i <= end; i++ {
+		points = append(points, c.Shades[shades[i]].Oklab())
+		if i > start {
+			segment := distance(points[len(points)-2], points[len(points)-1])
+			lengths = append(lengths, lengths[len(lengths)-1]+segment)
+		}
+	}
+
+	total := lengths[len(lengths)-1]
+	if total == 0 {
+		return
+	}
+
+	hue := c.Shades[shades[start]].H
+	resampled := make([]oklab.Oklab, len(points))
+	segment := 1
+	for i := 1; i < len(points)-1; i++ {
+		target := total * float64(i) / float64(len(points)-1)
+		for segment < len(lengths)-1 && lengths[segment] < target {
+			segment++
+		}
+		span := lengths[segment] - lengths[segment-1]
+		t := 0.0
+		if span > 0 {
+			t = (target - lengths[segment-1]) / span
+		}
+		a, b := points[segment-1], points[segment]
+		resampled[i] = oklab.Oklab{
+			L: a.L + (b.L-a.L)*t,
+			A: a.A + (b.A-a.A)*t,
+			B: a.B + (b.B-a.B)*t,
+		}
+	}
+
+	for i := 1; i < len(points)-1; i++ {
+		oklch := resampled[i].Oklch()
+		if oklch.C == 0 {
+			oklch.H = hue
+		}
+		detail := c.Shades[shades[start+i]]
+		detail.Oklch = oklab.Oklch{L: max(0, min(oklch.L, 1)), C: max(oklch.C, 0), H: oklch.H}
+		c.Shades[shades[start+i]] = detail
+	}
+}
+
+// ToReport writes a human readable uniformity report for the scale.
+func (u Uniformity) ToReport(w io.Writer) {
+	status := "ok"
+	if !u.OK() {
+		status = "check"
+	}
+	fmt.Fprintf(w, "%-9s anchor=%d mean=%.4f stddev=%.4f [%s]\n", u.Color, u.Anchor, u.Mean, u.StdDev, status)
+	for _, step := range u.NonMonotonic {
+		fmt.Fprintf(w, "  non-monotonic %3d→%-3d ΔL=%+.4f\n", step.From, step.To, step.DeltaL)
+	}
+	for _, step := range u.Uneven {
+		fmt.Fprintf(w, "  uneven        %3d→%-3d ΔE=%.4f (%+.0f%%)\n", step.From, step.To, step.DeltaE, (step.DeltaE/u.Mean-1)*100)
+	}
+}
@@ -0,0 +1,76 @@
+package palette
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/alltom/oklab"
+)
+
+// grayScale returns a neutral scale whose lightness falls by the given steps
+// from L=0.95 at shade 50.
+func grayScale(steps ...float64) *ColorDetails {
+	c := &ColorDetails{Color: Base, Anchor: 600, Shades: make(map[int]Details)}
+	l := 0.95
+	c.Shades[shades[0]] = Details{Oklch: oklab.Oklch{L: l}}
+	for i, step := range steps {
+		l -= step
+		c.Shades[shades[i+1]] = Details{Oklch: oklab.Oklch{L: l}}
+	}
+	return c
+}
+
+func TestUniformityEven(t *testing.T) {
+	c := grayScale(0.08, 0.08, 0.08, 0.08, 0.08, 0.08, 0.08, 0.08, 0.08, 0.08)
+	u := c.Uniformity(DefaultTolerance)
+	if !u.OK() {
+		t.Errorf("even scale reported %+v", u)
+	}
+	if len(u.Steps) != len(shades)-1 {
+		t.Fatalf("got %d steps, want %d", len(u.Steps), len(shades)-1)
+	}
+	if math.Abs(u.Mean-0.08) > 1e-9 || u.StdDev > 1e-9 {
+		t.Errorf("mean=%.4f stddev=%.4f, want 0.08 and 0", u.Mean, u.StdDev)
+	}
+	if step := u.Steps[0]; step.From != 50 || step.To != 100 || math.Abs(step.DeltaL+0.08) > 1e-9 {
+		t.Errorf("first step = %+v", step)
+	}
+}
+
+func TestUniformityReportsProblems(t *testing.T) {
+	// 200→300 gets lighter, 400→500 jumps by far more than the others
+	c := grayScale(0.05, 0.05, -0.02, 0.05, 0.3, 0.05, 0.05, 0.05, 0.05, 0.05)
+	u := c.Uniformity(DefaultTolerance)
+	if u.OK() {
+		t.Fatal("uneven scale reported ok")
+	}
+	if len(u.NonMonotonic) != 1 || u.NonMonotonic[0].From != 200 || u.NonMonotonic[0].To != 300 {
+		t.Errorf("NonMonotonic = %+v, want 200→300", u.NonMonotonic)
+	}
+	found := false
+	for _, step := range u.Uneven {
+		found = found || step.From == 400 && step.To == 500
+	}
+	if !found {
+		t.Errorf("Uneven = %+v, want 400→500 among them", u.Uneven)
+	}
+
+	var report strings.Builder
+	u.ToReport(&report)
+	for _, want := range []string{"base", "anchor=600", "[check]", "non-monotonic 200→300", "uneven        400→500"} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("report is missing %q:\n%s", want, report.String())
+		}
+	}
+}
+
+func TestUniformitySkipsMissingShades(t *testing.T) {
+	c := grayScale(0.1, 0.1)
+	if u := c.Uniformity(DefaultTolerance); len(u.Steps) != 2 || !u.OK() {
+		t.Errorf("partial scale = %+v", u)
+	}
+	if u := (&ColorDetails{Shades: map[int]Details{}}).Uniformity(DefaultTolerance); len(u.Steps) != 0 || u.Mean != 0 {
+		t.Errorf("empty scale = %+v", u)
+	}
+}
@@ -0,0 +1,176 @@
+// Package project reads hgmx.json, the optional configuration at the root of
+// an hgmx project. Every setting has a default matching the layout hgmx init
+// creates, so a project without the file works unchanged.
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the name of the project configuration file.
+const FileName = "hgmx.json"
+
+// Config is the project configuration. Paths are relative to Root.
+type Config struct {
+	Root     string   `json:"-"`
+	Views    string   `json:"views"`
+	CSS      CSS      `json:"css"`
+	Tailwind Tailwind `json:"tailwind"`
+	Fonts    Fonts    `json:"fonts"`
+	Icons    Icons    `json:"icons"`
+	Favicon  Favicon  `json:"favicon"`
+	Vendor   Vendor   `json:"vendor"`
+	Build    Build    `json:"build"`
+	Critical Critical `json:"critical"`
+	Dev      Dev      `json:"dev"`
+}
+
+// CSS locates the stylesheets built by hgmx css.
+type CSS struct {
+	Input    string `json:"input"`
+	Output   string `json:"output"`
+	Safelist string `json:"safelist"`
+	Minify   *bool  `json:"minify,omitempty"`
+}
+
+// Tailwind selects the standalone Tailwind CLI. Binary, when set, is used
+// instead of looking one up or downloading Version.
+type Tailwind struct {
+	Version string `json:"version"`
+	Binary  string `json:"binary,omitempty"`
+}
+
+// Fonts locates the self-hosted fonts, with their registry, and the
+// stylesheet hgmx fonts generates for them.
+type Fonts struct {
+	Dir string `json:"dir"`
+	CSS string `json:"css"`
+}
+
+// Icons locates the optimised icons, the sprite built from them and the Go
+// file declaring their names, whose directory name is its package.
+type Icons struct {
+	Dir    string `json:"dir"`
+	Sprite string `json:"sprite"`
+	Go     string `json:"go"`
+}
+
+// Favicon locates the icons and web app manifest hgmx favicon writes. Name
+// and ShortName fill the manifest, defaulting to the module name, and Shade
+// picks the --base-* color of its theme and background.
+type Favicon struct {
+	Dir       string `json:"dir"`
+	Name      string `json:"name,omitempty"`
+	ShortName string `json:"short_name,omitempty"`
+	Shade     int    `json:"shade"`
+}
+
+// Vendor locates the JavaScript libraries hgmx vendor manages, with their
+// manifest.
+type Vendor struct {
+	Dir string `json:"dir"`
+}
+
+// Build configures hgmx build, which bundles Static into Output. Budgets map
+// a file pattern, such as "*.js" or "css/*.css", to the most its matching
+// files may weigh together once compressed, such as "100kB".
+type Build struct {
+	Static  string            `json:"static"`
+	Output  string            `json:"output"`
+	Budgets map[string]string `json:"budgets,omitempty"`
+}
+
+// Critical configures hgmx css critical, which writes the CSS each of Pages
+// needs above the fold to Dir as <name>.css. Pages map a name to the Go
+// expression of its component, relative to the views, such as
+// "pages/home.Main()".
+type Critical struct {
+	Dir   string            `json:"dir"`
+	Pages map[string]string `json:"pages,omitempty"`
+}
+
+// Dev configures hgmx dev. The app is built from Package and must listen on
+// App, whose port it also receives as PORT; browsers open Proxy.
+type Dev struct {
+	Package string   `json:"package"`
+	Args    []string `json:"args,omitempty"`
+	App     string   `json:"app"`
+	Proxy   string   `json:"proxy"`
+}
+
+// Default returns the configuration of a project created by hgmx init in root.
+func Default(root string) *Config {
+	return &Config{
+		Root:  root,
+		Views: "views",
+		CSS: CSS{
+			Input:    "views/static/css/main.css",
+			Output:   "views/static/css/main.min.css",
+			Safelist: "views/static/css/safelist.css",
+		},
+		Tailwind: Tailwind{Version: "v4.1.4"},
+		Fonts: Fonts{
+			Dir: "views/static/fonts",
+			CSS: "views/static/css/fonts.css",
+		},
+		Icons: Icons{
+			Dir:    "views/icons",
+			Sprite: "views/static/icons/sprite.svg",
+			Go:     "views/components/display/icons.go",
+		},
+		Favicon: Favicon{
+			Dir:   "views/static/favicon",
+			Shade: 600,
+		},
+		Vendor: Vendor{Dir: "views/static/scripts/vendor"},
+		Build: Build{
+			Static: "views/static",
+			Output: "dist/static",
+			Budgets: map[string]string{
+				"*.css": "50kB",
+				"*.js":  "150kB",
+			},
+		},
+		Critical: Critical{Dir: "views/static/css/critical"},
+		Dev: Dev{
+			Package: ".",
+			App:     "localhost:8080",
+			Proxy:   "localhost:7331",
+		},
+	}
+}
+
+// Load reads hgmx.json in root over the defaults. A missing file is not an
+// error.
+func Load(root string) (*Config, error) {
+	c := Default(root)
+	b, err := os.ReadFile(filepath.Join(root, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", FileName, err)
+	}
+	return c, nil
+}
+
+// Path returns p resolved against the project root.
+func (c *Config) Path(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(c.Root, filepath.FromSlash(p))
+}
+
+// Minified reports whether built CSS is minified, true unless disabled.
+func (c CSS) Minified() bool {
+	return c.Minify == nil || *c.Minify
+}
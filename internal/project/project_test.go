@@ -0,0 +1,42 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c, Default(dir)) || !c.CSS.Minified() {
+		t.Errorf("Load() without %s = %+v, want the defaults", FileName, c)
+	}
+
+	config := `{"views": "app/views", "css": {"output": "dist/app.css", "minify": false}}`
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err = Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Views != "app/views" || c.CSS.Output != "dist/app.css" || c.CSS.Minified() {
+		t.Errorf("Load() = %+v, want the file's settings", c)
+	}
+	if c.CSS.Input != Default(dir).CSS.Input || c.Tailwind.Version == "" {
+		t.Errorf("Load() lost defaults not in the file: %+v", c)
+	}
+	if got, want := c.Path(c.CSS.Output), filepath.Join(dir, "dist", "app.css"); got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+
+	os.WriteFile(filepath.Join(dir, FileName), []byte("{"), 0o644)
+	if _, err := Load(dir); err == nil {
+		t.Error("Load() of invalid JSON succeeded")
+	}
+}
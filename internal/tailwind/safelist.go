@@ -0,0 +1,85 @@
+package tailwind
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// shadeRange is the Tailwind brace expansion of every palette shade.
+const shadeRange = "{50,{100..900..100},950}"
+
+var (
+	colorVar = regexp.MustCompile(`--color-([a-z]+)-500:`)
+
+	// dynamicPrefix matches a string literal that is only a color utility
+	// prefix, the tell of a class name completed at runtime, e.g.
+	// "bg-" + color + "-500".
+	dynamicPrefix = regexp.MustCompile(`"((?:[a-z-]+:)*(?:bg|text|border|ring|outline|fill|stroke|decoration|accent|caret|shadow|divide|placeholder|from|via|to)-)"`)
+)
+
+// Colors returns the palette color names defined in a colors.css, in order.
+func Colors(css []byte) []string {
+	var colors []string
+	for _, m := range colorVar.FindAllSubmatch(css, -1) {
+		if name := string(m[1]); !slices.Contains(colors, name) {
+			colors = append(colors, name)
+		}
+	}
+	return colors
+}
+
+// DynamicPrefixes scans the .templ and hand written .go files in fsys for
+// color utility prefixes that are completed at runtime, which Tailwind cannot
+// see. It returns them sorted.
+func DynamicPrefixes(fsys fs.FS) ([]string, error) {
+	var prefixes []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !strings.HasSuffix(p, ".templ") && (!strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_templ.go")) {
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		for _, m := range dynamicPrefix.FindAllSubmatch(b, -1) {
+			if prefix := string(m[1]); !slices.Contains(prefixes, prefix) {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+		return nil
+	})
+	slices.Sort(prefixes)
+	return prefixes, err
+}
+
+// WriteSafelist writes a safelist.css that makes Tailwind scan the .templ files
+// under sources, a path relative to the safelist, and generate every shade of
+// colors for each dynamic prefix.
+func WriteSafelist(w io.Writer, sources string, colors, prefixes []string) error {
+	var sb strings.Builder
+	sb.WriteString("/* Generated by hgmx css, do not edit. */\n")
+	fmt.Fprintf(&sb, "@source %q;\n", filepath.ToSlash(filepath.Join(sources, "**", "*.templ")))
+	if len(colors) > 0 {
+		for _, prefix := range prefixes {
+			fmt.Fprintf(&sb, "@source inline(%q);\n", prefix+braces(colors)+"-"+shadeRange)
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// braces returns items as a brace expansion, or the item itself if alone.
+func braces(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return "{" + strings.Join(items, ",") + "}"
+}
@@ -0,0 +1,194 @@
+// Package tailwind locates, downloads and runs the standalone Tailwind CLI, and
+// generates the safelist of palette classes built at runtime.
+package tailwind
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DefaultBaseURL is where standalone releases are downloaded from.
+const DefaultBaseURL = "https://github.com/tailwindlabs/tailwindcss/releases/download"
+
+// ErrOffline is returned by Locate when no binary is available and downloading
+// is not allowed.
+var ErrOffline = errors.New("tailwindcss is not installed or cached and offline mode is on")
+
+// CLI finds the tailwindcss binary to run.
+type CLI struct {
+	Version  string // release tag, e.g. "v4.1.4"
+	Binary   string // explicit path, used as is when set
+	CacheDir string // defaults to <user cache dir>/hgmx/tailwindcss
+	Offline  bool   // never download
+	BaseURL  string // defaults to DefaultBaseURL
+	Client   *http.Client
+
+	// LookPath searches PATH; defaults to exec.LookPath.
+	LookPath func(file string) (string, error)
+}
+
+// Locate returns the path of a tailwindcss binary: Binary if set, one on PATH,
+// or Version from the cache, downloading it first unless Offline.
+func (c *CLI) Locate(ctx context.Context) (string, error) {
+	if c.Binary != "" {
+		if _, err := os.Stat(c.Binary); err != nil {
+			return "", fmt.Errorf("tailwind binary: %w", err)
+		}
+		return c.Binary, nil
+	}
+
+	lookPath := c.LookPath
+	if lookPath == nil {
+		lookPath = exec.LookPath
+	}
+	if p, err := lookPath("tailwindcss"); err == nil {
+		return p, nil
+	}
+
+	asset, err := Asset(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+	cacheDir, err := c.cacheDir()
+	if err != nil {
+		return "", err
+	}
+	cached := filepath.Join(cacheDir, c.Version, asset)
+	if _, err := os.Stat(cached); err == nil {
+		return cached, nil
+	}
+
+	if c.Offline {
+		return "", fmt.Errorf("%w: install tailwindcss %s on PATH, set tailwind.binary in hgmx.json, or run once online to cache it in %s", ErrOffline, c.Version, cacheDir)
+	}
+	if err := c.download(ctx, asset, cached); err != nil {
+		return "", fmt.Errorf("download tailwindcss %s: %w", c.Version, err)
+	}
+	return cached, nil
+}
+
+// Asset returns the name of the standalone release for a platform.
+func Asset(goos, goarch string) (string, error) {
+	arch := map[string]string{"amd64": "x64", "arm64": "arm64"}[goarch]
+	osName := map[string]string{"linux": "linux", "darwin": "macos", "windows": "windows"}[goos]
+	if arch == "" || osName == "" || (goos == "windows" && goarch != "amd64") {
+		return "", fmt.Errorf("no tailwindcss standalone build for %s/%s", goos, goarch)
+	}
+	name := "tailwindcss-" + osName + "-" + arch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name, nil
+}
+
+func (c *CLI) cacheDir() (string, error) {
+	if c.CacheDir != "" {
+		return c.CacheDir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "hgmx", "tailwindcss"), nil
+}
+
+// download fetches asset and its checksum, verifies it and writes it to dst.
+func (c *CLI) download(ctx context.Context, asset, dst string) error {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	base += "/" + c.Version + "/"
+
+	sums, err := c.get(ctx, base+"sha256sums.txt")
+	if err != nil {
+		return err
+	}
+	want, err := checksum(sums, asset)
+	if err != nil {
+		return err
+	}
+	bin, err := c.get(ctx, base+asset)
+	if err != nil {
+		return err
+	}
+	if sum := sha256.Sum256(bin); hex.EncodeToString(sum[:]) != want {
+		return fmt.Errorf("checksum mismatch for %s", asset)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	tmp := dst + ".tmp"
+	if err := os.WriteFile(tmp, bin, 0o755); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+func (c *CLI) get(ctx context.Context, url string) ([]byte, error) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// checksum finds the hash of asset in a sha256sums.txt listing.
+func checksum(sums []byte, asset string) (string, error) {
+	s := bufio.NewScanner(bytes.NewReader(sums))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "./") == asset {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s", asset)
+}
+
+// Build describes one run of the Tailwind CLI.
+type Build struct {
+	Dir    string // working directory, the project root
+	Input  string
+	Output string
+	Minify bool
+	Watch  bool
+}
+
+// Command returns the command running bin for b.
+func (b Build) Command(ctx context.Context, bin string) *exec.Cmd {
+	args := []string{"-i", b.Input, "-o", b.Output}
+	if b.Minify {
+		args = append(args, "--minify")
+	}
+	if b.Watch {
+		args = append(args, "--watch")
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = b.Dir
+	return cmd
+}
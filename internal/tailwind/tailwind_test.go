@@ -0,0 +1,136 @@
+package tailwind
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func notOnPath(string) (string, error) { return "", errors.New("not found") }
+
+func TestAsset(t *testing.T) {
+	tests := []struct{ goos, goarch, want string }{
+		{"linux", "amd64", "tailwindcss-linux-x64"},
+		{"linux", "arm64", "tailwindcss-linux-arm64"},
+		{"darwin", "arm64", "tailwindcss-macos-arm64"},
+		{"windows", "amd64", "tailwindcss-windows-x64.exe"},
+		{"windows", "arm64", ""},
+		{"plan9", "amd64", ""},
+	}
+	for _, tt := range tests {
+		got, err := Asset(tt.goos, tt.goarch)
+		if got != tt.want || (tt.want == "") != (err != nil) {
+			t.Errorf("Asset(%s, %s) = %q, %v, want %q", tt.goos, tt.goarch, got, err, tt.want)
+		}
+	}
+}
+
+func TestLocate(t *testing.T) {
+	asset, err := Asset(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		t.Skip(err)
+	}
+	binary := []byte("#!/bin/sh\n")
+	sum := sha256.Sum256(binary)
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v4.0.0/sha256sums.txt":
+			w.Write([]byte(hex.EncodeToString(sum[:]) + "  ./" + asset + "\n"))
+		case "/v4.0.0/" + asset:
+			downloads++
+			w.Write(binary)
+		case "/v4.0.1/sha256sums.txt":
+			w.Write([]byte(strings.Repeat("0", 64) + "  ./" + asset + "\n"))
+		case "/v4.0.1/" + asset:
+			w.Write(binary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cache := t.TempDir()
+	cli := &CLI{Version: "v4.0.0", CacheDir: cache, BaseURL: srv.URL, LookPath: notOnPath, Offline: true}
+
+	if _, err := cli.Locate(ctx); !errors.Is(err, ErrOffline) {
+		t.Fatalf("offline Locate() = %v, want ErrOffline", err)
+	}
+
+	cli.Offline = false
+	p, err := cli.Locate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(cache, "v4.0.0", asset); p != want {
+		t.Errorf("Locate() = %q, want %q", p, want)
+	}
+	if info, err := os.Stat(p); err != nil || info.Mode()&0o100 == 0 {
+		t.Errorf("downloaded binary %v is not executable: %v", info, err)
+	}
+
+	cli.Offline = true
+	if _, err := cli.Locate(ctx); err != nil || downloads != 1 {
+		t.Errorf("cached Locate() = %v after %d downloads, want the cached binary", err, downloads)
+	}
+
+	bad := &CLI{Version: "v4.0.1", CacheDir: cache, BaseURL: srv.URL, LookPath: notOnPath}
+	if _, err := bad.Locate(ctx); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Locate() with a bad checksum = %v", err)
+	}
+
+	onPath := &CLI{Version: "v4.0.0", LookPath: func(string) (string, error) { return "/usr/bin/tailwindcss", nil }}
+	if p, err := onPath.Locate(ctx); err != nil || p != "/usr/bin/tailwindcss" {
+		t.Errorf("Locate() on PATH = %q, %v", p, err)
+	}
+
+	explicit := &CLI{Binary: filepath.Join(cache, "missing")}
+	if _, err := explicit.Locate(ctx); err == nil {
+		t.Error("Locate() with a missing explicit binary succeeded")
+	}
+}
+
+func TestSafelist(t *testing.T) {
+	colors := Colors([]byte(":root {\n--color-base-500: #000;\n--color-base-600: #000;\n--color-rose-500: #f00;\n}"))
+	if strings.Join(colors, ",") != "base,rose" {
+		t.Errorf("Colors() = %q", colors)
+	}
+
+	views := fstest.MapFS{
+		"a.templ":      {Data: []byte(`<p class={ "text-" + c + "-500" }>`)},
+		"b.go":         {Data: []byte(`return "hover:bg-" + c + "-600"`)},
+		"b_templ.go":   {Data: []byte(`"border-"`)},
+		"c.templ":      {Data: []byte(`<p class="bg-base-500 text-surface-400">`)},
+		"static/x.css": {Data: []byte(`"ring-"`)},
+	}
+	prefixes, err := DynamicPrefixes(views)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(prefixes, ",") != "hover:bg-,text-" {
+		t.Errorf("DynamicPrefixes() = %q", prefixes)
+	}
+
+	var sb strings.Builder
+	if err := WriteSafelist(&sb, "../..", colors, prefixes); err != nil {
+		t.Fatal(err)
+	}
+	want := `/* Generated by hgmx css, do not edit. */
+@source "../../**/*.templ";
+@source inline("hover:bg-{base,rose}-{50,{100..900..100},950}");
+@source inline("text-{base,rose}-{50,{100..900..100},950}");
+`
+	if sb.String() != want {
+		t.Errorf("WriteSafelist() =\n%s\nwant\n%s", sb.String(), want)
+	}
+}
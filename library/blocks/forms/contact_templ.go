@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.857
+// templ: version: v0.3.865
 package forms
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -8,7 +8,32 @@ package forms
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-func Contact() templ.Component {
+import (
+	"github.com/nosvagor/hgmx/form"
+	"github.com/nosvagor/hgmx/library/blocks/partials"
+)
+
+// ContactData is bound from a submitted Contact form with form.Parse.
+type ContactData struct {
+	Name    string `form:"name,trim" validate:"required,max=100"`
+	Email   string `form:"email,trim" validate:"required,email"`
+	Subject string `form:"subject,trim" validate:"max=120"`
+	Message string `form:"message" validate:"required,min=10,max=5000"`
+}
+
+// Fields returns the fields of the contact form, as LoginData.Fields.
+func (d ContactData) Fields(validate string, errs form.Errors) []partials.Field {
+	return []partials.Field{
+		{Name: "name", Label: "Name", Value: d.Name, Error: errs.Get("name"), Autocomplete: "name", Required: true, Validate: validate},
+		{Name: "email", Label: "Email", Type: "email", Value: d.Email, Error: errs.Get("email"), Autocomplete: "email", Required: true, Validate: validate},
+		{Name: "subject", Label: "Subject", Value: d.Subject, Error: errs.Get("subject"), Validate: validate},
+		{Name: "message", Label: "Message", Type: "textarea", Value: d.Message, Error: errs.Get("message"), Required: true, Validate: validate},
+	}
+}
+
+// Contact posts to action and replaces itself with the response; re-render it
+// with the bound data and errors as for Login.
+func Contact(action, validate string, d ContactData, errs form.Errors) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -29,6 +54,46 @@ func Contact() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<form action=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var2 templ.SafeURL = templ.URL(action)
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var2)))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\" method=\"post\" hx-post=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var3 string
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(action)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `library/blocks/forms/contact.templ`, Line: 29, Col: 66}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\" hx-target=\"this\" hx-swap=\"outerHTML\" class=\"flex flex-col gap-4\" novalidate>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = partials.CSRF().Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, f := range d.Fields(validate, errs) {
+			templ_7745c5c3_Err = partials.Formfield(f).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<button type=\"submit\" class=\"rounded bg-primary-500 px-4 py-2 text-base-600\">Send</button></form>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
 		return nil
 	})
 }
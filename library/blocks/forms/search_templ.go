@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.857
+// templ: version: v0.3.865
 package forms
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.857
+// templ: version: v0.3.865
 package forms
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -8,7 +8,34 @@ package forms
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-func Signup() templ.Component {
+import (
+	"strconv"
+
+	"github.com/nosvagor/hgmx/form"
+	"github.com/nosvagor/hgmx/library/blocks/partials"
+)
+
+// SignupData is bound from a submitted Signup form with form.Parse.
+type SignupData struct {
+	Name     string `form:"name,trim" validate:"required,max=64"`
+	Email    string `form:"email,trim" validate:"required,email"`
+	Password string `form:"password" validate:"required,min=12,max=128"`
+	Terms    bool   `form:"terms" validate:"required"`
+}
+
+// Fields returns the fields of the signup form, as LoginData.Fields.
+func (d SignupData) Fields(validate string, errs form.Errors) []partials.Field {
+	return []partials.Field{
+		{Name: "name", Label: "Name", Value: d.Name, Error: errs.Get("name"), Autocomplete: "name", Required: true, Validate: validate},
+		{Name: "email", Label: "Email", Type: "email", Value: d.Email, Error: errs.Get("email"), Autocomplete: "email", Required: true, Validate: validate},
+		{Name: "password", Label: "Password", Type: "password", Error: errs.Get("password"), Autocomplete: "new-password", Required: true},
+		{Name: "terms", Label: "I accept the terms", Type: "checkbox", Value: strconv.FormatBool(d.Terms), Error: errs.Get("terms"), Required: true},
+	}
+}
+
+// Signup posts to action and replaces itself with the response; re-render it
+// with the bound data and errors as for Login.
+func Signup(action, validate string, d SignupData, errs form.Errors) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -29,6 +56,46 @@ func Signup() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<form action=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var2 templ.SafeURL = templ.URL(action)
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var2)))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\" method=\"post\" hx-post=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var3 string
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(action)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `library/blocks/forms/signup.templ`, Line: 31, Col: 66}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\" hx-target=\"this\" hx-swap=\"outerHTML\" class=\"flex flex-col gap-4\" novalidate>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = partials.CSRF().Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, f := range d.Fields(validate, errs) {
+			templ_7745c5c3_Err = partials.Formfield(f).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<button type=\"submit\" class=\"rounded bg-primary-500 px-4 py-2 text-base-600\">Sign up</button></form>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
 		return nil
 	})
 }
@@ -0,0 +1,29 @@
+// Code generated by hgmx icons; DO NOT EDIT.
+
+package display
+
+// Icons in the sprite, for Icon.
+const (
+	IconAlertTriangle IconName = iota
+	IconCheck
+	IconChevronDown
+	IconChevronRight
+	IconInfo
+	IconMenu
+	IconPlus
+	IconSearch
+	IconX
+)
+
+// iconIDs are the sprite symbol ids of the IconName constants.
+var iconIDs = [...]string{
+	IconAlertTriangle: "alert-triangle",
+	IconCheck:         "check",
+	IconChevronDown:   "chevron-down",
+	IconChevronRight:  "chevron-right",
+	IconInfo:          "info",
+	IconMenu:          "menu",
+	IconPlus:          "plus",
+	IconSearch:        "search",
+	IconX:             "x",
+}
@@ -0,0 +1,125 @@
+package scripts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Source is where a library is published on npm and the name it is vendored
+// under. Vendored names do not follow the package: _hyperscript ships as
+// _hyperscript.min.js but is kept as hyperscript.min.js.
+type Source struct {
+	Package   string // npm package
+	Path      string // file in the package
+	File      string // vendored file, relative to the vendor directory
+	Extension bool
+}
+
+// Catalog lists the libraries hgmx vendor knows, by name.
+var Catalog = map[string]Source{
+	"htmx":             {Package: "htmx.org", Path: "dist/htmx.min.js", File: "htmx.min.js"},
+	"hyperscript":      {Package: "hyperscript.org", Path: "dist/_hyperscript.min.js", File: "hyperscript.min.js"},
+	"motion":           {Package: "motion", Path: "+esm", File: "motion.min.js"}, // an ES module bundled by jsDelivr
+	"sse":              {Package: "htmx-ext-sse", Path: "sse.js", File: "ext/sse.js", Extension: true},
+	"ws":               {Package: "htmx-ext-ws", Path: "ws.js", File: "ext/ws.js", Extension: true},
+	"preload":          {Package: "htmx-ext-preload", Path: "preload.js", File: "ext/preload.js", Extension: true},
+	"response-targets": {Package: "htmx-ext-response-targets", Path: "response-targets.js", File: "ext/response-targets.js", Extension: true},
+}
+
+// Names returns the catalog names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(Catalog))
+	for name := range Catalog {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ParseSpec splits "htmx@2.0.4" into a catalog name and version. The version
+// is empty when omitted.
+func ParseSpec(spec string) (name, version string, err error) {
+	name, version, _ = strings.Cut(spec, "@")
+	if _, ok := Catalog[name]; !ok {
+		return "", "", fmt.Errorf("unknown library %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	return name, version, nil
+}
+
+// Fetcher downloads libraries from a CDN serving npm packages and resolves
+// versions from the npm registry.
+type Fetcher struct {
+	Client   *http.Client
+	CDN      string // e.g. https://cdn.jsdelivr.net/npm/
+	Registry string // e.g. https://registry.npmjs.org/
+}
+
+// NewFetcher returns a Fetcher using jsDelivr and the npm registry.
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		Client:   http.DefaultClient,
+		CDN:      "https://cdn.jsdelivr.net/npm/",
+		Registry: "https://registry.npmjs.org/",
+	}
+}
+
+// Latest returns the version npm tags latest for the library called name.
+func (f *Fetcher) Latest(ctx context.Context, name string) (string, error) {
+	src, ok := Catalog[name]
+	if !ok {
+		return "", fmt.Errorf("unknown library %q", name)
+	}
+	b, err := f.get(ctx, f.Registry+src.Package+"/latest")
+	if err != nil {
+		return "", err
+	}
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(b, &pkg); err != nil || pkg.Version == "" {
+		return "", fmt.Errorf("%s: no latest version in the registry response", src.Package)
+	}
+	return pkg.Version, nil
+}
+
+// Fetch downloads version of the library called name and describes it.
+func (f *Fetcher) Fetch(ctx context.Context, name, version string) (Library, []byte, error) {
+	src, ok := Catalog[name]
+	if !ok {
+		return Library{}, nil, fmt.Errorf("unknown library %q", name)
+	}
+	url := f.CDN + src.Package + "@" + version + "/" + src.Path
+	b, err := f.get(ctx, url)
+	if err != nil {
+		return Library{}, nil, err
+	}
+	return Library{
+		Name:      name,
+		Version:   version,
+		File:      src.File,
+		URL:       url,
+		Integrity: Integrity(b),
+		Extension: src.Extension,
+	}, b, nil
+}
+
+func (f *Fetcher) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,101 @@
+// Package scripts tracks the JavaScript libraries vendored into the static
+// scripts directory. A manifest next to the files records the version, source
+// and Subresource Integrity hash of each, so the layout can link them with an
+// integrity attribute and hgmx vendor can tell what to update.
+package scripts
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+// ManifestName is the manifest kept next to the vendored files.
+const ManifestName = "vendor.json"
+
+// Manifest lists the vendored libraries.
+type Manifest struct {
+	Libraries []Library `json:"libraries"`
+}
+
+// Library is one vendored file.
+type Library struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	File      string `json:"file"` // relative to the vendor directory
+	URL       string `json:"url"`
+	Integrity string `json:"integrity"`
+	// Extension marks htmx extensions, which the layout loads after htmx.
+	Extension bool `json:"extension,omitempty"`
+}
+
+// Integrity returns the SRI hash of data, as used in integrity attributes.
+func Integrity(data []byte) string {
+	sum := sha512.Sum384(data)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Load reads the manifest name from fsys. A missing manifest is empty.
+func Load(fsys fs.FS, name string) (*Manifest, error) {
+	m := &Manifest{}
+	b, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return m, nil
+}
+
+// Get returns the library called name.
+func (m *Manifest) Get(name string) (Library, bool) {
+	i := slices.IndexFunc(m.Libraries, func(l Library) bool { return l.Name == name })
+	if i < 0 {
+		return Library{}, false
+	}
+	return m.Libraries[i], true
+}
+
+// Add adds l, replacing a library with the same name.
+func (m *Manifest) Add(l Library) {
+	if i := slices.IndexFunc(m.Libraries, func(o Library) bool { return o.Name == l.Name }); i >= 0 {
+		m.Libraries[i] = l
+		return
+	}
+	m.Libraries = append(m.Libraries, l)
+}
+
+// Marshal encodes the manifest for writing back to disk.
+func (m *Manifest) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(m, "", "  ")
+	return append(b, '\n'), err
+}
+
+// Integrities maps each file, relative to the vendor directory, to its SRI
+// hash.
+func (m *Manifest) Integrities() map[string]string {
+	out := make(map[string]string, len(m.Libraries))
+	for _, l := range m.Libraries {
+		out[l.File] = l.Integrity
+	}
+	return out
+}
+
+// Extensions returns the files of the vendored htmx extensions.
+func (m *Manifest) Extensions() []string {
+	var files []string
+	for _, l := range m.Libraries {
+		if l.Extension {
+			files = append(files, l.File)
+		}
+	}
+	return files
+}
@@ -0,0 +1,94 @@
+package scripts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIntegrity(t *testing.T) {
+	// openssl dgst -sha384 -binary | openssl base64 -A, of the empty input
+	if got, want := Integrity(nil), "sha384-OLBgp1GsljhM2TJ+sbHjaiH9txEUvgdDTAzHv2P24donTt6/529l+9Ua0vFImLlb"; got != want {
+		t.Errorf("Integrity() = %s, want %s", got, want)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	fsys := fstest.MapFS{ManifestName: {Data: []byte(`{"libraries":[
+		{"name":"htmx","version":"2.0.4","file":"htmx.min.js","integrity":"sha384-a"},
+		{"name":"sse","version":"2.2.2","file":"ext/sse.js","integrity":"sha384-b","extension":true}
+	]}`)}}
+	m, err := Load(fsys, ManifestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Integrities()["ext/sse.js"]; got != "sha384-b" {
+		t.Errorf("Integrities()[ext/sse.js] = %q", got)
+	}
+	if got := m.Extensions(); !slices.Equal(got, []string{"ext/sse.js"}) {
+		t.Errorf("Extensions() = %v", got)
+	}
+
+	m.Add(Library{Name: "htmx", Version: "2.0.5", File: "htmx.min.js"})
+	if l, _ := m.Get("htmx"); l.Version != "2.0.5" || len(m.Libraries) != 2 {
+		t.Errorf("Add() did not replace htmx: %+v", m.Libraries)
+	}
+
+	if m, err := Load(fstest.MapFS{}, ManifestName); err != nil || len(m.Libraries) != 0 {
+		t.Errorf("Load() without a manifest = %+v, %v", m, err)
+	}
+	if _, err := Load(fstest.MapFS{ManifestName: {Data: []byte(`{`)}}, ManifestName); err == nil {
+		t.Error("Load() accepted invalid JSON")
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	for spec, want := range map[string][2]string{
+		"htmx@2.0.4":       {"htmx", "2.0.4"},
+		"hyperscript":      {"hyperscript", ""},
+		"response-targets": {"response-targets", ""},
+	} {
+		name, version, err := ParseSpec(spec)
+		if err != nil || name != want[0] || version != want[1] {
+			t.Errorf("ParseSpec(%q) = %q, %q, %v", spec, name, version, err)
+		}
+	}
+	if _, _, err := ParseSpec("jquery@3"); err == nil {
+		t.Error("ParseSpec() accepted an unknown library")
+	}
+}
+
+func TestFetcher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/registry/hyperscript.org/latest":
+			w.Write([]byte(`{"name":"hyperscript.org","version":"0.9.14"}`))
+		case "/cdn/hyperscript.org@0.9.14/dist/_hyperscript.min.js":
+			w.Write([]byte("_hyperscript"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	f := &Fetcher{Client: srv.Client(), CDN: srv.URL + "/cdn/", Registry: srv.URL + "/registry/"}
+	ctx := context.Background()
+
+	version, err := f.Latest(ctx, "hyperscript")
+	if err != nil || version != "0.9.14" {
+		t.Fatalf("Latest() = %q, %v", version, err)
+	}
+	l, b, err := f.Fetch(ctx, "hyperscript", version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.File != "hyperscript.min.js" || l.Integrity != Integrity(b) || string(b) != "_hyperscript" {
+		t.Errorf("Fetch() = %+v, %q", l, b)
+	}
+
+	if _, _, err := f.Fetch(ctx, "htmx", "9.9.9"); err == nil {
+		t.Error("Fetch() of a missing version succeeded")
+	}
+}
@@ -0,0 +1,181 @@
+// Package sse pushes server-sent events, typically rendered templ fragments, to
+// browsers connected through the htmx sse extension.
+// See https://htmx.org/extensions/sse/
+package sse
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// Event is a single server-sent event. ID is assigned by the Broker on Publish.
+type Event struct {
+	ID    string
+	Name  string // event type, matched by sse-swap; empty means "message"
+	Data  string
+	Retry time.Duration
+}
+
+// Option configures a Broker.
+type Option func(*Broker)
+
+// WithReplay sets how many events per topic are kept for Last-Event-ID replay.
+func WithReplay(n int) Option {
+	return func(b *Broker) { b.replay = max(n, 0) }
+}
+
+// WithHeartbeat sets the interval of keep-alive comments sent to idle clients.
+// Zero disables heartbeats.
+func WithHeartbeat(d time.Duration) Option {
+	return func(b *Broker) { b.heartbeat = d }
+}
+
+// WithClientBuffer sets how many events may queue for a single client. A client
+// that falls further behind is disconnected and replays on reconnect.
+func WithClientBuffer(n int) Option {
+	return func(b *Broker) { b.clientBuffer = max(n, 1) }
+}
+
+// Broker fans events out to the clients subscribed to a topic.
+type Broker struct {
+	mu     sync.Mutex
+	topics map[string]*topic
+	lastID uint64
+
+	replay       int
+	heartbeat    time.Duration
+	clientBuffer int
+}
+
+type topic struct {
+	clients map[*client]struct{}
+	history []Event
+}
+
+type client struct {
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (c *client) close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// NewBroker returns a Broker keeping 64 events per topic for replay, sending a
+// heartbeat every 15 seconds and buffering 16 events per client.
+func NewBroker(opts ...Option) *Broker {
+	b := &Broker{
+		topics:       make(map[string]*topic),
+		replay:       64,
+		heartbeat:    15 * time.Second,
+		clientBuffer: 16,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Publish assigns e the next event id and sends it to every client subscribed
+// to name. Clients whose buffer is full are disconnected rather than blocking
+// the publisher.
+func (b *Broker) Publish(name string, e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e.ID = strconv.FormatUint(b.lastID, 10)
+
+	t := b.topic(name)
+	if b.replay > 0 {
+		t.history = append(t.history, e)
+		if len(t.history) > b.replay {
+			t.history = t.history[len(t.history)-b.replay:]
+		}
+	}
+
+	for c := range t.clients {
+		select {
+		case c.events <- e:
+		default:
+			delete(t.clients, c)
+			c.close()
+		}
+	}
+	return e
+}
+
+// PublishComponent renders component and publishes it as an event named event.
+func (b *Broker) PublishComponent(ctx context.Context, name, event string, component templ.Component) (Event, error) {
+	var buf bytes.Buffer
+	if err := component.Render(ctx, &buf); err != nil {
+		return Event{}, err
+	}
+	return b.Publish(name, Event{Name: event, Data: buf.String()}), nil
+}
+
+// Subscribe registers a client on topic name. Buffered events published after
+// lastEventID are queued first; pass "" to skip replay. done is closed when
+// cancel is called or the client falls behind, after which no more events are
+// delivered.
+func (b *Broker) Subscribe(name, lastEventID string) (events <-chan Event, done <-chan struct{}, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topic(name)
+	var backlog []Event
+	if lastEventID != "" {
+		if last, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+			for _, e := range t.history {
+				if id, _ := strconv.ParseUint(e.ID, 10, 64); id > last {
+					backlog = append(backlog, e)
+				}
+			}
+		}
+	}
+
+	c := &client{events: make(chan Event, b.clientBuffer+len(backlog)), done: make(chan struct{})}
+	for _, e := range backlog {
+		c.events <- e
+	}
+	t.clients[c] = struct{}{}
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if t, ok := b.topics[name]; ok {
+			delete(t.clients, c)
+			if len(t.clients) == 0 && len(t.history) == 0 {
+				delete(b.topics, name)
+			}
+		}
+		c.close()
+	}
+	return c.events, c.done, cancel
+}
+
+// Clients returns the number of clients subscribed to topic name.
+func (b *Broker) Clients(name string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t, ok := b.topics[name]; ok {
+		return len(t.clients)
+	}
+	return 0
+}
+
+// topic returns the topic called name, creating it if needed. b.mu must be held.
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{clients: make(map[*client]struct{})}
+		b.topics[name] = t
+	}
+	return t
+}
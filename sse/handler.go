@@ -0,0 +1,91 @@
+package sse
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// lineBreaks normalises CRLF and CR, which both end a line in an event stream.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// stripLineBreaks drops CR and LF from a single-line field, where they would end
+// the field early and let the rest be read as fields of its own.
+var stripLineBreaks = strings.NewReplacer("\r", "", "\n", "")
+
+// WriteTo writes e as a text/event-stream frame. Multi-line data is split over
+// several data fields; line breaks in ID and Name are dropped.
+func (e Event) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	if id := stripLineBreaks.Replace(e.ID); id != "" {
+		fmt.Fprintf(&sb, "id: %s\n", id)
+	}
+	if name := stripLineBreaks.Replace(e.Name); name != "" {
+		fmt.Fprintf(&sb, "event: %s\n", name)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&sb, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	data := lineBreaks.Replace(e.Data)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&sb, "data: %s\n", line)
+	}
+	sb.WriteString("\n")
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// Handler returns an http.Handler that streams topic name to each client.
+func (b *Broker) Handler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.Serve(w, r, name)
+	})
+}
+
+// Serve streams topic name to the client until it disconnects or falls behind.
+// Events missed since the Last-Event-ID request header are replayed first.
+func (b *Broker) Serve(w http.ResponseWriter, r *http.Request, name string) {
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	events, done, cancel := b.Subscribe(name, r.Header.Get("Last-Event-ID"))
+	defer cancel()
+
+	var heartbeat <-chan time.Time
+	if b.heartbeat > 0 {
+		ticker := time.NewTicker(b.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-done:
+			return
+		case <-heartbeat:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case e := <-events:
+			if _, err := e.WriteTo(w); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
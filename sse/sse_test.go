@@ -0,0 +1,235 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// connect opens a stream to srv, sending lastEventID unless it is empty, and
+// waits until the broker has counted the client.
+func connect(t *testing.T, b *Broker, srv *httptest.Server, lastEventID string) (*bufio.Reader, context.CancelFunc) {
+	t.Helper()
+	before := b.Clients("news")
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cancel(); resp.Body.Close() })
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	waitFor(t, func() bool { return b.Clients("news") > before })
+	return bufio.NewReader(resp.Body), cancel
+}
+
+// serve starts a server streaming topic "news", closed after the connections of
+// the test.
+func serve(t *testing.T, b *Broker) *httptest.Server {
+	srv := httptest.NewServer(b.Handler("news"))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// readFrame reads the next frame, up to its blank line, without the newlines.
+func readFrame(t *testing.T, r *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading frame after %q: %v", lines, err)
+		}
+		if line == "\n" {
+			return lines
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	var sb strings.Builder
+	e := Event{ID: "7", Name: "stats", Data: "<p>a</p>\r\n<p>b</p>\r<p>c</p>", Retry: 2 * time.Second}
+	if _, err := e.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := "id: 7\nevent: stats\nretry: 2000\ndata: <p>a</p>\ndata: <p>b</p>\ndata: <p>c</p>\n\n"
+	if sb.String() != want {
+		t.Errorf("WriteTo() = %q, want %q", sb.String(), want)
+	}
+
+	sb.Reset()
+	e = Event{ID: "1\r\ndata: x", Name: "stats\nevent: other", Data: "ok"}
+	if _, err := e.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want = "id: 1data: x\nevent: statsevent: other\ndata: ok\n\n"
+	if sb.String() != want {
+		t.Errorf("line breaks in ID and Name: WriteTo() = %q, want %q", sb.String(), want)
+	}
+}
+
+func TestServe(t *testing.T) {
+	b := NewBroker()
+	srv := serve(t, b)
+
+	r, _ := connect(t, b, srv, "")
+	b.Publish("news", Event{Name: "stats", Data: "one\ntwo"})
+	b.Publish("other", Event{Data: "not for us"})
+	b.Publish("news", Event{Data: "three"})
+
+	want := [][]string{
+		{"id: 1", "event: stats", "data: one", "data: two"},
+		{"id: 3", "data: three"},
+	}
+	for _, w := range want {
+		if got := readFrame(t, r); strings.Join(got, "|") != strings.Join(w, "|") {
+			t.Errorf("frame = %q, want %q", got, w)
+		}
+	}
+}
+
+func TestServeReplay(t *testing.T) {
+	b := NewBroker(WithReplay(3))
+	srv := serve(t, b)
+
+	for _, data := range []string{"a", "b", "c", "d", "e"} {
+		b.Publish("news", Event{Data: data})
+	}
+
+	// ids 1 and 2 have fallen out of the history, so only 3-5 can be replayed
+	for lastID, want := range map[string][]string{"1": {"3", "4", "5"}, "3": {"4", "5"}} {
+		r, cancel := connect(t, b, srv, lastID)
+		for _, id := range want {
+			if got := readFrame(t, r); got[0] != "id: "+id {
+				t.Errorf("Last-Event-ID %s: frame = %q, want id %s", lastID, got, id)
+			}
+		}
+		// the next frame is live, not a replayed one
+		b.Publish("news", Event{Data: "live"})
+		if got := readFrame(t, r); got[1] != "data: live" {
+			t.Errorf("Last-Event-ID %s: frame after the replay = %q, want the live event", lastID, got)
+		}
+		cancel()
+		waitFor(t, func() bool { return b.Clients("news") == 0 })
+	}
+
+	if b.Publish("news", Event{}); len(b.topics["news"].history) != 3 {
+		t.Errorf("history holds %d events, want 3", len(b.topics["news"].history))
+	}
+}
+
+func TestServeHeartbeat(t *testing.T) {
+	b := NewBroker(WithHeartbeat(10 * time.Millisecond))
+	srv := serve(t, b)
+
+	r, _ := connect(t, b, srv, "")
+	if got := readFrame(t, r); len(got) != 1 || got[0] != ": heartbeat" {
+		t.Errorf("idle stream sent %q, want a heartbeat comment", got)
+	}
+}
+
+func TestDropSlowClient(t *testing.T) {
+	b := NewBroker(WithClientBuffer(2))
+	events, done, cancel := b.Subscribe("news", "")
+	defer cancel()
+
+	for range 3 {
+		b.Publish("news", Event{Data: "x"})
+	}
+	select {
+	case <-done:
+	default:
+		t.Fatal("client behind by more than its buffer is still subscribed")
+	}
+	if n := b.Clients("news"); n != 0 {
+		t.Errorf("Clients() = %d after the drop, want 0", n)
+	}
+	if len(events) != 2 {
+		t.Errorf("%d events queued, want the 2 that fit the buffer", len(events))
+	}
+
+	// a stalled connection holds up its handler, which is dropped and returns
+	w := &stalledWriter{ResponseRecorder: httptest.NewRecorder(), gate: make(chan struct{})}
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		b.Serve(w, httptest.NewRequest(http.MethodGet, "/", nil), "news")
+	}()
+	waitFor(t, func() bool { return b.Clients("news") == 1 })
+	b.Publish("news", Event{Data: "x"}) // taken by the handler, stuck writing
+	waitFor(t, w.writing)
+	for range 3 {
+		b.Publish("news", Event{Data: "x"})
+	}
+	if n := b.Clients("news"); n != 0 {
+		t.Errorf("Clients() = %d with a stalled handler, want 0", n)
+	}
+	close(w.gate)
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve kept running after its client was dropped")
+	}
+}
+
+// stalledWriter blocks every Write until gate is closed.
+type stalledWriter struct {
+	*httptest.ResponseRecorder
+	gate    chan struct{}
+	mu      sync.Mutex
+	blocked bool
+}
+
+func (w *stalledWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	w.blocked = true
+	w.mu.Unlock()
+	<-w.gate
+	return w.ResponseRecorder.Write(p)
+}
+
+func (w *stalledWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *stalledWriter) writing() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.blocked
+}
+
+func TestServeUnsubscribesOnCancel(t *testing.T) {
+	b := NewBroker(WithReplay(0))
+	srv := serve(t, b)
+
+	_, cancel := connect(t, b, srv, "")
+	connect(t, b, srv, "")
+	if n := b.Clients("news"); n != 2 {
+		t.Fatalf("Clients() = %d, want 2", n)
+	}
+	cancel()
+	waitFor(t, func() bool { return b.Clients("news") == 1 })
+}
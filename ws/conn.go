@@ -0,0 +1,124 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// ErrClosed is returned when sending to a closed connection.
+	ErrClosed = errors.New("ws: connection closed")
+	// ErrSlow is returned when a connection's send buffer is full. The
+	// connection is closed so it cannot hold up its room.
+	ErrSlow = errors.New("ws: client too slow, connection closed")
+)
+
+// Conn is a client connected through the htmx ws extension. Sends are queued
+// and written by a single goroutine, so they never block on the network.
+type Conn struct {
+	// Request is the upgrade request, e.g. for reading the session cookie.
+	Request *http.Request
+
+	ws   *websocket.Conn
+	send chan []byte
+	done chan struct{}
+	once sync.Once
+}
+
+func newConn(r *http.Request, ws *websocket.Conn, buffer int) *Conn {
+	return &Conn{
+		Request: r,
+		ws:      ws,
+		send:    make(chan []byte, buffer),
+		done:    make(chan struct{}),
+	}
+}
+
+// Send renders components into a single message and queues it. Top level
+// elements are swapped into the elements with the same id, so send htmx.OOB
+// values or components whose root carries an id or hx-swap-oob.
+func (c *Conn) Send(ctx context.Context, components ...templ.Component) error {
+	data, err := render(ctx, components)
+	if err != nil {
+		return err
+	}
+	return c.queue(data)
+}
+
+// Close closes the connection. It is safe to call more than once.
+func (c *Conn) Close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// Done is closed once the connection is closed.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// queue adds data to the send buffer without blocking, closing the connection
+// when the buffer is full.
+func (c *Conn) queue(data []byte) error {
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+	select {
+	case c.send <- data:
+		return nil
+	default:
+		c.Close()
+		return ErrSlow
+	}
+}
+
+// writeLoop writes queued messages and pings until the connection is closed,
+// then closes the socket, which also ends the read loop.
+func (c *Conn) writeLoop(ping, writeWait time.Duration) {
+	defer c.ws.Close()
+
+	var tick <-chan time.Time
+	if ping > 0 {
+		ticker := time.NewTicker(ping)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-c.done:
+			c.ws.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(writeWait))
+			return
+		case data := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.Close()
+				return
+			}
+		case <-tick:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+func render(ctx context.Context, components []templ.Component) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, component := range components {
+		if err := component.Render(ctx, &buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,92 @@
+// Package ws serves the htmx ws extension: it decodes the form payloads htmx
+// sends over a WebSocket, routes them by trigger to handlers and pushes
+// rendered templ fragments back, which htmx swaps in by id.
+// See https://htmx.org/extensions/ws/
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/nosvagor/hgmx/htmx"
+)
+
+// Message is a form submitted through ws-send. htmx sends the form values and
+// its request headers as one JSON object, the headers under "HEADERS".
+type Message struct {
+	Headers http.Header
+	Values  url.Values
+}
+
+// Decode parses a message sent by the htmx ws extension. Values may be strings,
+// arrays for repeated fields, or any other JSON value, kept as its JSON text.
+func Decode(data []byte) (*Message, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	m := &Message{Headers: make(http.Header), Values: make(url.Values)}
+	for key, value := range raw {
+		if key == "HEADERS" {
+			var headers map[string]any
+			if err := json.Unmarshal(value, &headers); err != nil {
+				return nil, err
+			}
+			for k, v := range headers {
+				if v != nil {
+					m.Headers.Set(k, scalar(v))
+				}
+			}
+			continue
+		}
+
+		var list []any
+		if json.Unmarshal(value, &list) == nil {
+			for _, v := range list {
+				m.Values.Add(key, scalar(v))
+			}
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, err
+		}
+		m.Values.Set(key, scalar(v))
+	}
+	return m, nil
+}
+
+// scalar returns a decoded JSON value as the string a form field would hold.
+func scalar(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// Trigger returns the name of the element that sent the message, or its id
+// when it has no name. Messages are routed on this value.
+func (m *Message) Trigger() string {
+	if name := m.Headers.Get(htmx.HeaderTriggerName); name != "" {
+		return name
+	}
+	return m.Headers.Get(htmx.HeaderTrigger)
+}
+
+// Target returns the id of the target element of the trigger, if any.
+func (m *Message) Target() string {
+	return m.Headers.Get(htmx.HeaderTarget)
+}
+
+// CurrentURL returns the URL of the page that sent the message.
+func (m *Message) CurrentURL() string {
+	return m.Headers.Get(htmx.HeaderCurrentURL)
+}
+
+// Get returns the first value of form field key.
+func (m *Message) Get(key string) string {
+	return m.Values.Get(key)
+}
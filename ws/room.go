@@ -0,0 +1,75 @@
+package ws
+
+import (
+	"context"
+	"sync"
+
+	"github.com/a-h/templ"
+)
+
+// Room is a set of connections that receive the same broadcasts. Connections
+// leave automatically when they close.
+type Room struct {
+	mu    sync.Mutex
+	conns map[*Conn]struct{}
+}
+
+// NewRoom returns an empty Room.
+func NewRoom() *Room {
+	return &Room{conns: make(map[*Conn]struct{})}
+}
+
+// Join adds c to the room until it closes or Leave is called.
+func (r *Room) Join(c *Conn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.conns[c]; ok {
+		return
+	}
+	r.conns[c] = struct{}{}
+	go func() {
+		<-c.Done()
+		r.Leave(c)
+	}()
+}
+
+// Leave removes c from the room.
+func (r *Room) Leave(c *Conn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.conns, c)
+}
+
+// Len returns the number of connections in the room.
+func (r *Room) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.conns)
+}
+
+// Broadcast renders components once and queues the result for every connection
+// in the room. Connections whose send buffer is full are closed rather than
+// blocking the others.
+func (r *Room) Broadcast(ctx context.Context, components ...templ.Component) error {
+	return r.BroadcastExcept(ctx, nil, components...)
+}
+
+// BroadcastExcept is Broadcast skipping except, typically the sender.
+func (r *Room) BroadcastExcept(ctx context.Context, except *Conn, components ...templ.Component) error {
+	data, err := render(ctx, components)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for c := range r.conns {
+		if c == except {
+			continue
+		}
+		if err := c.queue(data); err != nil {
+			delete(r.conns, c)
+		}
+	}
+	return nil
+}
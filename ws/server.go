@@ -0,0 +1,143 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// HandlerFunc handles a message from c. Returning an error closes the
+// connection.
+type HandlerFunc func(ctx context.Context, c *Conn, m *Message) error
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithSendBuffer sets how many messages may queue for a single connection. A
+// connection that falls further behind is closed.
+func WithSendBuffer(n int) Option {
+	return func(s *Server) { s.sendBuffer = max(n, 1) }
+}
+
+// WithPing sets the interval of pings sent to keep connections alive and
+// detect dead peers. Zero disables pings and read deadlines.
+func WithPing(d time.Duration) Option {
+	return func(s *Server) { s.ping = d }
+}
+
+// WithReadLimit sets the largest message, in bytes, a client may send.
+func WithReadLimit(n int64) Option {
+	return func(s *Server) { s.readLimit = n }
+}
+
+// WithCheckOrigin replaces the default same-origin check of the upgrade.
+func WithCheckOrigin(check func(r *http.Request) bool) Option {
+	return func(s *Server) { s.upgrader.CheckOrigin = check }
+}
+
+// OnConnect registers fn to run for every new connection before its messages
+// are read, e.g. to join it to a Room or send the initial state.
+func OnConnect(fn func(ctx context.Context, c *Conn)) Option {
+	return func(s *Server) { s.onConnect = fn }
+}
+
+// Server upgrades requests to WebSocket connections and routes their messages
+// to handlers by trigger.
+type Server struct {
+	upgrader websocket.Upgrader
+	routes   map[string]HandlerFunc
+	notFound HandlerFunc
+
+	sendBuffer int
+	ping       time.Duration
+	writeWait  time.Duration
+	readLimit  int64
+	onConnect  func(ctx context.Context, c *Conn)
+}
+
+// NewServer returns a Server buffering 16 messages per connection, pinging
+// every 30 seconds and accepting messages up to 64KiB.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		routes:     make(map[string]HandlerFunc),
+		sendBuffer: 16,
+		ping:       30 * time.Second,
+		writeWait:  10 * time.Second,
+		readLimit:  64 << 10,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Handle routes messages whose trigger, the name or else the id of the element
+// that sent them, is trigger to h.
+//
+//	<form id="chat" ws-send><input name="text"/></form>
+//	s.Handle("chat", func(ctx context.Context, c *ws.Conn, m *ws.Message) error { ... })
+func (s *Server) Handle(trigger string, h HandlerFunc) {
+	s.routes[trigger] = h
+}
+
+// NotFound sets the handler for messages without a route. By default they are
+// ignored.
+func (s *Server) NotFound(h HandlerFunc) {
+	s.notFound = h
+}
+
+// ServeHTTP upgrades the request and handles the connection's messages, one at
+// a time and in order, until it closes.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	socket, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // the upgrader has already replied with an error
+	}
+
+	c := newConn(r, socket, s.sendBuffer)
+	defer c.Close()
+	go c.writeLoop(s.ping, s.writeWait)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		<-c.Done()
+		cancel()
+	}()
+
+	socket.SetReadLimit(s.readLimit)
+	if s.ping > 0 {
+		wait := s.ping * 2
+		socket.SetReadDeadline(time.Now().Add(wait))
+		socket.SetPongHandler(func(string) error {
+			return socket.SetReadDeadline(time.Now().Add(wait))
+		})
+	}
+
+	if s.onConnect != nil {
+		s.onConnect(ctx, c)
+	}
+
+	for {
+		_, data, err := socket.ReadMessage()
+		if err != nil {
+			return
+		}
+		m, err := Decode(data)
+		if err != nil {
+			continue
+		}
+		h, ok := s.routes[m.Trigger()]
+		if !ok {
+			h = s.notFound
+		}
+		if h == nil {
+			continue
+		}
+		if err := h(ctx, c, m); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gorilla/websocket"
+	"github.com/nosvagor/hgmx/htmx"
+)
+
+func text(s string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	})
+}
+
+func TestDecode(t *testing.T) {
+	data := `{"text":"hi","tags":["a","b"],"n":3,"HEADERS":{"HX-Request":"true","HX-Trigger":"chat","HX-Trigger-Name":null,"HX-Target":"log","HX-Current-URL":"http://x/room"}}`
+	m, err := Decode([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Trigger(); got != "chat" {
+		t.Errorf("Trigger() = %q, want chat", got)
+	}
+	if got := m.Target(); got != "log" {
+		t.Errorf("Target() = %q, want log", got)
+	}
+	if got := m.CurrentURL(); got != "http://x/room" {
+		t.Errorf("CurrentURL() = %q", got)
+	}
+	if got := m.Get("text"); got != "hi" {
+		t.Errorf("text = %q, want hi", got)
+	}
+	if got := strings.Join(m.Values["tags"], ","); got != "a,b" {
+		t.Errorf("tags = %q, want a,b", got)
+	}
+	if got := m.Get("n"); got != "3" {
+		t.Errorf("n = %q, want 3", got)
+	}
+
+	m, _ = Decode([]byte(`{"HEADERS":{"HX-Trigger":"f1","HX-Trigger-Name":"save"}}`))
+	if got := m.Trigger(); got != "save" {
+		t.Errorf("Trigger() = %q, want the name save", got)
+	}
+
+	if _, err := Decode([]byte(`[1]`)); err == nil {
+		t.Error("Decode([1]) succeeded, want error")
+	}
+}
+
+func TestRoomBackpressure(t *testing.T) {
+	room := NewRoom()
+	fast := newConn(nil, nil, 4)
+	slow := newConn(nil, nil, 1)
+	room.Join(fast)
+	room.Join(slow)
+
+	ctx := context.Background()
+	for i := range 2 {
+		if err := room.Broadcast(ctx, text("x")); err != nil {
+			t.Fatalf("broadcast %d: %v", i, err)
+		}
+	}
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("slow connection still open after its buffer filled")
+	}
+	if got := room.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+	if got := len(fast.send); got != 2 {
+		t.Errorf("fast connection queued %d messages, want 2", got)
+	}
+	if err := slow.Send(ctx, text("x")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Send on closed connection = %v, want ErrClosed", err)
+	}
+
+	fast.Close()
+	deadline := time.Now().Add(time.Second)
+	for room.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := room.Len(); got != 0 {
+		t.Errorf("Len() = %d after close, want 0", got)
+	}
+}
+
+func TestServer(t *testing.T) {
+	room := NewRoom()
+	s := NewServer(OnConnect(func(ctx context.Context, c *Conn) { room.Join(c) }))
+	s.Handle("chat", func(ctx context.Context, c *Conn, m *Message) error {
+		return room.Broadcast(ctx, htmx.OOB{Target: "log", Swap: htmx.SwapBeforeEnd, Component: text(m.Get("text"))})
+	})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+	a, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for room.Len() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	msg := `{"text":"hello","HEADERS":{"HX-Request":"true","HX-Trigger":"chat","HX-Trigger-Name":null}}`
+	if err := a.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<div hx-swap-oob="beforeend:#log">hello</div>`
+	for _, c := range []*websocket.Conn{a, b} {
+		c.SetReadDeadline(time.Now().Add(time.Second))
+		_, data, err := c.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != want {
+			t.Errorf("message = %q, want %q", got, want)
+		}
+	}
+}